@@ -58,14 +58,12 @@ func main() {
 	}
 	cmd.Start()
 	// Get the initial winsize
-	myTerm.Winsz(os.Stdin)
-	myTerm.Winsz(pty.Slave)
+	term.CopySize(pty.Slave, os.Stdin)
 	// If the termsize changes , propagate to our PTY
 	for {
 		switch <-sig {
 		case syscall.SIGWINCH:
-			myTerm.Winsz(os.Stdin)
-			myTerm.Setwinsz(pty.Slave)
+			term.CopySize(pty.Slave, os.Stdin)
 		default:
 			return
 		}
@@ -0,0 +1,77 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "os"
+
+// Option is a change to terminal attributes, applied by With and Configure.
+//
+//	err := term.Configure(f, term.WithRaw(), term.WithVMin(0), term.WithSpeed(115200))
+type Option struct {
+	apply func(t *Termios)
+	err   error
+}
+
+// Err returns why the option isn't valid, eg. WithSpeed of an unsupported rate, nil if it is.
+func (o Option) Err() error {
+	return o.err
+}
+
+// With returns a copy of t with opts applied in order, t itself is not changed.
+// Options that aren't valid are skipped, Configure reports them.
+func (t Termios) With(opts ...Option) Termios {
+	for _, o := range opts {
+		if o.err == nil && o.apply != nil {
+			o.apply(&t)
+		}
+	}
+	return t
+}
+
+// Configure gets the attributes of the terminal f, applies opts and sets the result.
+// If any of opts isn't valid its error is returned before anything is changed.
+func Configure(f *os.File, opts ...Option) error {
+	for _, o := range opts {
+		if o.err != nil {
+			return o.err
+		}
+	}
+	t, err := Attr(f)
+	if err != nil {
+		return err
+	}
+	t = t.With(opts...)
+	return t.Set(f)
+}
+
+// WithRaw puts the terminal in raw mode, see Raw.
+func WithRaw() Option {
+	return Option{apply: (*Termios).Raw}
+}
+
+// WithEcho turns echo of input characters on or off, see SetEcho.
+func WithEcho(on bool) Option {
+	return Option{apply: func(t *Termios) { t.SetEcho(on) }}
+}
+
+// WithVMin sets the minimum number of bytes for a non-canonical read, see SetVMin.
+func WithVMin(n byte) Option {
+	return Option{apply: func(t *Termios) { t.SetVMin(n) }}
+}
+
+// WithVTime sets the non-canonical read timeout in tenths of a second, see SetVTime.
+func WithVTime(n byte) Option {
+	return Option{apply: func(t *Termios) { t.SetVTime(n) }}
+}
+
+// WithSpeed sets both the input and output baud rate, see SetSpeed.
+// The option isn't valid if baud isn't a supported speed.
+func WithSpeed(baud int) Option {
+	code, err := baudCode(baud)
+	if err != nil {
+		return Option{err: err}
+	}
+	return Option{apply: func(t *Termios) { t.setSpeed(code, code) }}
+}
@@ -0,0 +1,34 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "testing"
+
+// TestWith tests applying options to a copy of the attributes.
+func TestWith(t *testing.T) {
+	var tr Termios
+	tr.Lflag = ICANON | ECHO | ISIG
+	orig := tr
+	got := tr.With(WithRaw(), WithEcho(true), WithVMin(0), WithVTime(5), WithSpeed(9600))
+	if tr != orig {
+		t.Errorf("With changed the original: %v want: %v", tr, orig)
+	}
+	if got.Lflag&(ICANON|ISIG) != 0 || got.Lflag&ECHO == 0 {
+		t.Errorf("With(WithRaw, WithEcho(true)) got Lflag: %#o want ECHO only", got.Lflag)
+	}
+	if got.VMin() != 0 || got.VTime() != 5 || got.OutputSpeed() != 9600 {
+		t.Errorf("With got VMin: %d VTime: %d speed: %d want: 0 5 9600", got.VMin(), got.VTime(), got.OutputSpeed())
+	}
+	bad := WithSpeed(9601)
+	if bad.Err() == nil {
+		t.Errorf("WithSpeed(9601).Err() got: <nil> want: not a supported speed")
+	}
+	if WithRaw().Err() != nil {
+		t.Errorf("WithRaw().Err() got: %v want: <nil>", WithRaw().Err())
+	}
+	if got := tr.With(bad, Option{}); got != tr {
+		t.Errorf("With of invalid options got: %v want: %v", got, tr)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ColorSupport is how many colors a terminal can show.
+type ColorSupport int
+
+// Color support levels, from none to 24bit truecolor.
+const (
+	ColorNone ColorSupport = iota // ColorNone no colors, not a terminal or a dumb one
+	Color16                       // Color16 the basic 8 colors and their bright versions
+	Color256                      // Color256 the xterm 256 color palette
+	ColorTrue                     // ColorTrue 24bit RGB colors
+)
+
+// String implements the Stringer interface.
+func (c ColorSupport) String() string {
+	switch c {
+	case ColorNone:
+		return "none"
+	case Color16:
+		return "16"
+	case Color256:
+		return "256"
+	case ColorTrue:
+		return "truecolor"
+	}
+	return "ColorSupport(" + strconv.Itoa(int(c)) + ")"
+}
+
+// ColorLevel classifies the colors the terminal f supports by the TERM and COLORTERM environment
+// variables and, when they don't tell for sure, by asking the terminal as QueryColorSupport does.
+// A dumb or missing TERM gives ColorNone and truecolor in COLORTERM or TERM gives ColorTrue without
+// asking. Otherwise the terminal's answer is waited for up to replyTimeout, if it doesn't reply
+// the guess from the environment is returned.
+// Only a tty gets any colors, if f isn't one ColorNone is returned and nothing is asked.
+func ColorLevel(f *os.File) ColorSupport {
+	guess := colorLevelGuess(f)
+	if guess == ColorNone || guess == ColorTrue {
+		return guess
+	}
+	if colors, err := queryColors(f, replyTimeout); err == nil {
+		return colors
+	}
+	return guess
+}
+
+// colorLevelGuess is ColorLevel only looking at the environment.
+func colorLevelGuess(f *os.File) ColorSupport {
+	if !Isatty(f) {
+		return ColorNone
+	}
+	return colorLevelEnv(os.Getenv("TERM"), os.Getenv("COLORTERM"))
+}
+
+// colorLevelEnv classifies the terminal by the values of TERM and COLORTERM.
+func colorLevelEnv(term, colorterm string) ColorSupport {
+	switch {
+	case term == "" || term == "dumb":
+		return ColorNone
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return ColorTrue
+	case strings.HasSuffix(term, "-direct") || strings.Contains(term, "truecolor"):
+		return ColorTrue
+	case strings.Contains(term, "256color"):
+		return Color256
+	}
+	return Color16
+}
+
+// colorsRequest is the XTGETTCAP request for the terminfo colors capability, "colors" hex encoded.
+const colorsRequest = "\x1bP+q636f6c6f7273\x1b\\"
+
+// QueryColorSupport asks the terminal f how many colors it has using the XTGETTCAP request for
+// the terminfo colors capability. Unlike ColorLevel this works in terminals not setting COLORTERM.
+// If the terminal doesn't reply within timeout, or doesn't know the capability, the guess
+// ColorLevel makes from the environment is returned.
+func QueryColorSupport(f *os.File, timeout time.Duration) (ColorSupport, error) {
+	colors, err := queryColors(f, timeout)
+	if err == ErrTimeout {
+		return colorLevelGuess(f), nil
+	}
+	return colors, err
+}
+
+// queryColors is QueryColorSupport giving ErrTimeout if the terminal doesn't reply.
+func queryColors(f *os.File, timeout time.Duration) (ColorSupport, error) {
+	reply, err := Query(f, colorsRequest, '\\', timeout)
+	if err != nil {
+		return ColorNone, err
+	}
+	tcap, ok := replyBody(reply, "\x1bP")
+	if !ok || !bytes.HasSuffix(reply, []byte(st)) {
+		return ColorNone, errors.New("colors reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	if bytes.HasPrefix(tcap, []byte("0+r")) {
+		return colorLevelGuess(f), nil
+	}
+	_, val, ok := bytes.Cut(tcap, []byte("="))
+	if !ok || !bytes.HasPrefix(tcap, []byte("1+r")) {
+		return ColorNone, errors.New("colors reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	dec, err := hex.DecodeString(string(val))
+	if err != nil {
+		return ColorNone, err
+	}
+	colors, err := strconv.Atoi(string(dec))
+	if err != nil {
+		return ColorNone, err
+	}
+	return colorLevelCount(colors), nil
+}
+
+// colorLevelCount classifies the terminal by the number of colors it has.
+func colorLevelCount(colors int) ColorSupport {
+	switch {
+	case colors >= 1<<24:
+		return ColorTrue
+	case colors >= 256:
+		return Color256
+	case colors >= 8:
+		return Color16
+	}
+	return ColorNone
+}
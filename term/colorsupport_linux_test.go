@@ -0,0 +1,130 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"testing"
+	"time"
+)
+
+// TestColorLevel tests classifying the terminal colors from the environment and the terminal's answer.
+func TestColorLevel(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	tsts := []struct {
+		term, colorterm string
+		want            ColorSupport
+	}{
+		{"", "", ColorNone},
+		{"dumb", "truecolor", ColorNone},
+		{"xterm", "", Color16},
+		{"vt100", "", Color16},
+		{"xterm-256color", "", Color256},
+		{"screen-256color", "", Color256},
+		{"xterm-256color", "truecolor", ColorTrue},
+		{"xterm", "24bit", ColorTrue},
+		{"xterm-direct", "", ColorTrue},
+	}
+	for _, tst := range tsts {
+		t.Setenv("TERM", tst.term)
+		t.Setenv("COLORTERM", tst.colorterm)
+		if got := colorLevelGuess(p.Slave); got != tst.want {
+			t.Errorf("colorLevelGuess TERM=%q COLORTERM=%q got: %v want: %v", tst.term, tst.colorterm, got, tst.want)
+		}
+	}
+	// Nothing replies, the guess from the environment is kept.
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORTERM", "")
+	if got := ColorLevel(p.Slave); got != Color256 {
+		t.Errorf("ColorLevel with no reply got: %v want: %v", got, Color256)
+	}
+	for _, tst := range []struct {
+		term, reply string
+		want        ColorSupport
+	}{
+		{"xterm", "\x1bP1+r636f6c6f7273=3136373737323136\x1b\\", ColorTrue},
+		{"xterm-256color", "\x1bP1+r636f6c6f7273=38\x1b\\", Color16},
+		{"xterm-256color", "\x1bP0+r636f6c6f7273\x1b\\", Color256},
+	} {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		t.Setenv("TERM", tst.term)
+		t.Setenv("COLORTERM", "")
+		answerQuery(p, colorsRequest, tst.reply)
+		if got := ColorLevel(p.Slave); got != tst.want {
+			t.Errorf("ColorLevel TERM=%q reply: %q got: %v want: %v", tst.term, tst.reply, got, tst.want)
+		}
+		p.Close()
+	}
+	// Decided by the environment the terminal isn't asked, that would wait for the reply.
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORTERM", "truecolor")
+	start := time.Now()
+	ColorLevel(p.Slave)
+	if time.Since(start) > replyTimeout/2 {
+		t.Errorf("ColorLevel with COLORTERM=truecolor took: %v", time.Since(start))
+	}
+	f, err := donormfile("TestColorLevel")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestColorLevel\") failed: %v", err)
+	}
+	defer f.Close()
+	if got := ColorLevel(f); got != ColorNone {
+		t.Errorf("ColorLevel for normal file got: %v want: %v", got, ColorNone)
+	}
+	if got, want := ColorSupport(7).String(), "ColorSupport(7)"; got != want {
+		t.Errorf("ColorSupport(7).String() got: %q want: %q", got, want)
+	}
+}
+
+// TestQueryColorSupport tests reading the colors from the XTGETTCAP reply.
+func TestQueryColorSupport(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORTERM", "")
+	tsts := []struct {
+		reply string
+		want  ColorSupport
+		fail  bool
+	}{
+		{reply: "\x1bP1+r636f6c6f7273=3136373737323136\x1b\\", want: ColorTrue},
+		{reply: "\x1bP1+r636f6c6f7273=323536\x1b\\", want: Color256},
+		{reply: "typed\x1bP1+r636f6c6f7273=38\x1b\\", want: Color16},
+		{reply: "\x1bP1+r636f6c6f7273=30\x1b\\", want: ColorNone},
+		{reply: "\x1bP0+r636f6c6f7273\x1b\\", want: Color16},
+		{reply: "\x1bP1+r636f6c6f7273=zz\x1b\\", fail: true},
+		{reply: "\x1bP1+r636f6c6f7273\x1b\\", fail: true},
+	}
+	for _, tst := range tsts {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		answerQuery(p, colorsRequest, tst.reply)
+		got, err := QueryColorSupport(p.Slave, replyTimeout)
+		if tst.fail {
+			if err == nil {
+				t.Errorf("QueryColorSupport reply: %q got: <nil> want: error", tst.reply)
+			}
+		} else if err != nil || got != tst.want {
+			t.Errorf("QueryColorSupport reply: %q got: %v,%v want: %v,<nil>", tst.reply, got, err, tst.want)
+		}
+		p.Close()
+	}
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	t.Setenv("TERM", "xterm-256color")
+	if got, err := QueryColorSupport(p.Slave, 200*time.Millisecond); err != nil || got != Color256 {
+		t.Errorf("QueryColorSupport with no reply got: %v,%v want: %v,<nil>", got, err, Color256)
+	}
+}
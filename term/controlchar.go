@@ -0,0 +1,79 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ControlCharName names one of the special characters of a terminal.
+type ControlCharName int
+
+// Special characters for ControlChar and SetControlChar.
+const (
+	CharEOF     ControlCharName = iota // CharEOF char will send EOF
+	CharIntr                           // CharIntr char will send an interrupt signal
+	CharQuit                           // CharQuit char will send a quit signal
+	CharErase                          // CharErase char will erase last typed char
+	CharKill                           // CharKill char will erase current line
+	CharSusp                           // CharSusp char will send a stop signal
+	CharEOL                            // CharEOL char will end the line
+	CharEOL2                           // CharEOL2 char alternate to end line
+	CharWerase                         // CharWerase char will erase last word typed
+	CharReprint                        // CharReprint char will redraw the current line
+	CharLnext                          // CharLnext char will enter the next char quoted
+	CharDiscard                        // CharDiscard char will toggle discarding output
+	CharStart                          // CharStart char will restart output after stopping it
+	CharStop                           // CharStop char will stop output
+)
+
+// ccIndex maps the ControlCharNames to their platform index in Termios.Cc.
+// The V constants differ between Linux and the BSDs, see controlchar_bsd.go and controlchar_other.go.
+var ccIndex = [...]int{
+	CharEOF:     VEOF,
+	CharIntr:    VINTR,
+	CharQuit:    VQUIT,
+	CharErase:   VERASE,
+	CharKill:    VKILL,
+	CharSusp:    VSUSP,
+	CharEOL:     VEOL,
+	CharEOL2:    VEOL2,
+	CharWerase:  VWERASE,
+	CharReprint: VREPRINT,
+	CharLnext:   VLNEXT,
+	CharDiscard: VDISCARD,
+	CharStart:   VSTART,
+	CharStop:    VSTOP,
+}
+
+// ControlChar returns the special character name of terminal t.
+// An error is returned if name isn't one of the ControlCharNames.
+func (t *Termios) ControlChar(name ControlCharName) (byte, error) {
+	i, err := ccIndexOf(name)
+	if err != nil {
+		return 0, err
+	}
+	return t.Cc[i], nil
+}
+
+// SetControlChar sets the special character name of terminal t to c.
+// Only t is changed, use Set to apply it. An error is returned if name isn't one of the ControlCharNames.
+func (t *Termios) SetControlChar(name ControlCharName, c byte) error {
+	i, err := ccIndexOf(name)
+	if err != nil {
+		return err
+	}
+	t.Cc[i] = c
+	return nil
+}
+
+// ccIndexOf returns the index in Termios.Cc of name.
+func ccIndexOf(name ControlCharName) (int, error) {
+	if name < 0 || int(name) >= len(ccIndex) {
+		return 0, errors.New("control char: " + strconv.Itoa(int(name)) + " not a valid ControlCharName")
+	}
+	return ccIndex[name], nil
+}
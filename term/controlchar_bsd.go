@@ -0,0 +1,24 @@
+//go:build darwin || freebsd
+
+package term
+
+// Control characters, indexes into Termios.Cc as used by Darwin and FreeBSD.
+const (
+	VEOF     = 0  // VEOF 		char will send EOF
+	VEOL     = 1  // VEOL 		char will end the line
+	VEOL2    = 2  // VEOL2 		char alternate to end line
+	VERASE   = 3  // VEREASE 	char will erase last typed char
+	VWERASE  = 4  // VWERASE 	char will erase last word typed
+	VKILL    = 5  // VKILL 		char will erase current line
+	VREPRINT = 6  // VREPRINT will redraw the current line
+	VINTR    = 8  // VINTR 		char will send an interrupt signal
+	VQUIT    = 9  // VQUIT 		char will send a quit signal
+	VSUSP    = 10 // VSUSP 		char will send a stop signal
+	VSTART   = 12 // VSTART 	char will restart output after stopping it
+	VSTOP    = 13 // VSTOP 		char will stop output
+	VLNEXT   = 14 // VLNEXT 	char will enter the next char quoted
+	VDISCARD = 15 // VDISCARD
+	VMIN     = 16 // VMIN 		set min characters for a complete read
+	VTIME    = 17 // VTIME 		set read timeout in tenths of seconds
+	VSWTC    = -1 // VSWTC 		no such char on the BSDs, left out of the stty output and ssh modes
+)
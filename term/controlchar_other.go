@@ -0,0 +1,24 @@
+//go:build !darwin && !freebsd
+
+package term
+
+// Control characters, indexes into Termios.Cc as used by Linux.
+const (
+	VINTR    = 0  // VINTR 		char will send an interrupt signal
+	VQUIT    = 1  // VQUIT 		char will send a quit signal
+	VERASE   = 2  // VEREASE 	char will erase last typed char
+	VKILL    = 3  // VKILL 		char will erase current line
+	VEOF     = 4  // VEOF 		char will send EOF
+	VTIME    = 5  // VTIME 		set read timeout in tenths of seconds
+	VMIN     = 6  // VMIN 		set min characters for a complete read
+	VSWTC    = 7  // VSWTC 		char will switch to a different shell layer
+	VSTART   = 8  // VSTART 	char will restart output after stopping it
+	VSTOP    = 9  // VSTOP 		char will stop output
+	VSUSP    = 10 // VSUSP 		char will send a stop signal
+	VEOL     = 11 // VEOL 		char will end the line
+	VREPRINT = 12 // VREPRINT will redraw the current line
+	VDISCARD = 13 // VDISCARD
+	VWERASE  = 14 // VWERASE 	char will erase last word typed
+	VLNEXT   = 15 // VLNEXT 	char will enter the next char quoted
+	VEOL2    = 16 // VEOL2 		char alternate to end line
+)
@@ -0,0 +1,26 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "testing"
+
+// TestControlCharRange tests names out of the ControlCharName range being refused.
+func TestControlCharRange(t *testing.T) {
+	var tr Termios
+	for _, name := range []ControlCharName{-1, CharStop + 1, 99} {
+		if _, err := tr.ControlChar(name); err == nil {
+			t.Errorf("ControlChar(%d) got: <nil> want: not a valid ControlCharName", name)
+		}
+		if err := tr.SetControlChar(name, 'X'); err == nil {
+			t.Errorf("SetControlChar(%d) got: <nil> want: not a valid ControlCharName", name)
+		}
+	}
+	if err := tr.SetControlChar(CharStop, 'S'); err != nil {
+		t.Fatalf("SetControlChar(CharStop) failed: %v", err)
+	}
+	if c, err := tr.ControlChar(CharStop); err != nil || c != 'S' {
+		t.Errorf("ControlChar(CharStop) got: %q,%v want: 'S',<nil>", c, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"os"
+	"sync"
+)
+
+// Controller serializes changes to the terminal attributes and size of a file.
+// The free functions like Attr and Set do read-modify-write of the attributes with no locking,
+// two goroutines doing that on the same terminal, eg. one reading input and one handling resizes,
+// can lose each other's changes. The Controller methods are safe for concurrent use, each one runs
+// to completion before another starts. They only guard against each other, changes made to the
+// file by other means are not.
+type Controller struct {
+	mu       sync.Mutex
+	f        *os.File
+	saved    *Termios         // saved the attributes before MakeRaw, nil if not raw
+	features map[Feature]bool // features the SupportsFeature answers of the terminal
+}
+
+// NewController returns a Controller for the terminal f.
+func NewController(f *os.File) *Controller {
+	return &Controller{f: f}
+}
+
+// File returns the file of the Controller.
+func (c *Controller) File() *os.File {
+	return c.f
+}
+
+// Attr gets the attributes of the terminal.
+func (c *Controller) Attr() (Termios, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Attr(c.f)
+}
+
+// Set sets the attributes of the terminal to t.
+func (c *Controller) Set(t *Termios) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return t.Set(c.f)
+}
+
+// Update gets the attributes of the terminal, lets fn change them and sets the result.
+// No other Controller method runs in between so changes done this way are never lost.
+func (c *Controller) Update(fn func(t *Termios)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := Attr(c.f)
+	if err != nil {
+		return err
+	}
+	fn(&t)
+	return t.Set(c.f)
+}
+
+// MakeRaw puts the terminal in raw mode saving the attributes for Restore.
+// If already put in raw mode the attributes saved the first time are kept.
+func (c *Controller) MakeRaw() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, err := Attr(c.f)
+	if err != nil {
+		return err
+	}
+	raw := t
+	raw.Raw()
+	if err := raw.Set(c.f); err != nil {
+		return err
+	}
+	if c.saved == nil {
+		c.saved = &t
+	}
+	return nil
+}
+
+// Restore sets the attributes saved by MakeRaw, it does nothing if MakeRaw wasn't called.
+func (c *Controller) Restore() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.saved == nil {
+		return nil
+	}
+	if err := c.saved.Set(c.f); err != nil {
+		return err
+	}
+	c.saved = nil
+	return nil
+}
+
+// SetEcho turns echo of input characters on or off.
+func (c *Controller) SetEcho(on bool) error {
+	return c.Update(func(t *Termios) {
+		t.SetEcho(on)
+	})
+}
+
+// GetSize gets the number of rows and columns of the terminal.
+func (c *Controller) GetSize() (rows, cols int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return GetSize(c.f)
+}
+
+// Resize sets the number of rows and columns of the terminal, see SetSize.
+func (c *Controller) Resize(rows, cols int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return SetSize(c.f, rows, cols)
+}
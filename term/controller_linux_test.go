@@ -0,0 +1,80 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestController tests changing the terminal from several goroutines.
+func TestController(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	c := NewController(tty.Slave)
+	cooked, err := c.Attr()
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if err := c.Restore(); err != nil {
+		t.Errorf("Restore before MakeRaw failed: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := c.MakeRaw(); err != nil {
+				t.Errorf("MakeRaw failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := c.Resize(24+i, 80); err != nil {
+				t.Errorf("Resize failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// Each goroutine sets its own bit, none of them get lost.
+			bit := byte(1) << i
+			if err := c.Update(func(t *Termios) { t.Cc[VSTART] |= bit }); err != nil {
+				t.Errorf("Update failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	got, err := c.Attr()
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if got.Lflag&(ICANON|ECHO) != 0 {
+		t.Errorf("MakeRaw got Lflag: %#o want raw mode", got.Lflag)
+	}
+	if got.Cc[VSTART] != cooked.Cc[VSTART]|0xff {
+		t.Errorf("Update got Cc[VSTART]: %#x want: %#x", got.Cc[VSTART], cooked.Cc[VSTART]|0xff)
+	}
+	if rows, cols, err := c.GetSize(); err != nil || rows < 24 || rows > 31 || cols != 80 {
+		t.Errorf("GetSize got: %d,%d,%v want: 24-31,80,<nil>", rows, cols, err)
+	}
+	if err := c.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got, err := Attr(tty.Slave); err != nil || got.Lflag != cooked.Lflag {
+		t.Errorf("Restore got Lflag: %#o,%v want: %#o", got.Lflag, err, cooked.Lflag)
+	}
+	if err := c.SetEcho(false); err != nil {
+		t.Fatalf("SetEcho(false) failed: %v", err)
+	}
+	if got, err := Attr(tty.Slave); err != nil || got.Lflag&ECHO != 0 {
+		t.Errorf("SetEcho(false) got Lflag: %#o,%v want ECHO cleared", got.Lflag, err)
+	}
+	if c.File() != tty.Slave {
+		t.Errorf("File got: %v want: %v", c.File(), tty.Slave)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"io"
+)
+
+// crlfWriter is the io.Writer returned by CRLFWriter.
+type crlfWriter struct {
+	w   io.Writer
+	cr  bool
+	buf []byte
+}
+
+// CRLFWriter returns a writer passing what's written to it on to w with every \n not already
+// preceded by \r turned into \r\n. It's for writing to a terminal with output processing off,
+// see SetOutputProcessing, where a \n only moves down a line and not back to the first column.
+// A \r ending one write and \n starting the next are taken as a pair.
+// It's not safe for concurrent use.
+func CRLFWriter(w io.Writer) io.Writer {
+	return &crlfWriter{w: w}
+}
+
+// Write implements the io.Writer interface, on success len(p) is returned.
+func (cw *crlfWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	cw.buf = cw.buf[:0]
+	// prev is the byte before rest, for the first \n of p it's from the write before.
+	prev := cw.cr
+	for rest := p; len(rest) > 0; {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			cw.buf = append(cw.buf, rest...)
+			break
+		}
+		if i > 0 {
+			prev = rest[i-1] == '\r'
+		}
+		cw.buf = append(cw.buf, rest[:i]...)
+		if !prev {
+			cw.buf = append(cw.buf, '\r')
+		}
+		cw.buf = append(cw.buf, '\n')
+		prev = false
+		rest = rest[i+1:]
+	}
+	cw.cr = p[len(p)-1] == '\r'
+	if _, err := cw.w.Write(cw.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
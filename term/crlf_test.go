@@ -0,0 +1,40 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestCRLFWriter tests turning the lone newlines into CRLF.
+func TestCRLFWriter(t *testing.T) {
+	tsts := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"no newline"}, "no newline"},
+		{[]string{"one\ntwo\n"}, "one\r\ntwo\r\n"},
+		{[]string{"\n\n"}, "\r\n\r\n"},
+		{[]string{"already\r\nfine"}, "already\r\nfine"},
+		{[]string{"split\r", "\npair"}, "split\r\npair"},
+		{[]string{"lone\r", "x\n"}, "lone\rx\r\n"},
+		{[]string{"a\n", "", "\nb"}, "a\r\n\r\nb"},
+		{[]string{"split\r", "\n\n"}, "split\r\n\r\n"},
+		{[]string{"cr\r", "x\n\n"}, "cr\rx\r\n\r\n"},
+	}
+	for _, tst := range tsts {
+		var out bytes.Buffer
+		w := CRLFWriter(&out)
+		for _, in := range tst.in {
+			if n, err := w.Write([]byte(in)); err != nil || n != len(in) {
+				t.Errorf("Write(%q) got: %d,%v want: %d,<nil>", in, n, err, len(in))
+			}
+		}
+		if got := out.String(); got != tst.want {
+			t.Errorf("CRLFWriter %q got: %q want: %q", tst.in, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Feature names an optional capability of a terminal.
+type Feature int
+
+// Features for SupportsFeature.
+const (
+	FeatureBracketedPaste Feature = iota // FeatureBracketedPaste bracketed paste mode, see SetBracketedPaste
+	FeatureMouseSGR                      // FeatureMouseSGR mouse events in the SGR format, see SetMouseMode
+	FeatureTrueColor                     // FeatureTrueColor 24bit RGB colors
+	FeatureSixel                         // FeatureSixel sixel graphics
+)
+
+// String implements the Stringer interface.
+func (feat Feature) String() string {
+	switch feat {
+	case FeatureBracketedPaste:
+		return "bracketed paste"
+	case FeatureMouseSGR:
+		return "SGR mouse"
+	case FeatureTrueColor:
+		return "truecolor"
+	case FeatureSixel:
+		return "sixel"
+	}
+	return "Feature(" + strconv.Itoa(int(feat)) + ")"
+}
+
+// SupportsFeature asks the terminal f whether it supports feat.
+// The modes are probed with a DECRQM request, sixel graphics with the primary device attributes and
+// truecolor as QueryColorSupport does. The DECRQM request is followed by ESC[c so a terminal not
+// knowing it is found out by the device attributes reply coming alone, without waiting for a timeout.
+// The terminal is asked each time, Controller.SupportsFeature remembers the answers.
+// ErrTimeout is returned if it doesn't reply, for truecolor the guess ColorLevel makes from the
+// environment is returned instead.
+func SupportsFeature(f *os.File, feat Feature) (bool, error) {
+	ok, _, err := probeFeature(f, feat)
+	return ok, err
+}
+
+// SupportsFeature asks the terminal whether it supports feat, see the SupportsFeature function.
+// What the terminal answered is kept in c so it is only asked once for each feature,
+// a truecolor guess made when it didn't reply isn't kept.
+func (c *Controller) SupportsFeature(feat Feature) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ok, cached := c.features[feat]; cached {
+		return ok, nil
+	}
+	ok, answered, err := probeFeature(c.f, feat)
+	if err != nil || !answered {
+		return ok, err
+	}
+	if c.features == nil {
+		c.features = map[Feature]bool{}
+	}
+	c.features[feat] = ok
+	return ok, nil
+}
+
+// probeFeature asks the terminal f whether it supports feat, answered is false for a guess.
+func probeFeature(f *os.File, feat Feature) (ok, answered bool, err error) {
+	switch feat {
+	case FeatureBracketedPaste:
+		ok, err = modeSupported(f, 2004)
+	case FeatureMouseSGR:
+		ok, err = modeSupported(f, 1006)
+	case FeatureTrueColor:
+		var colors ColorSupport
+		colors, err = queryColors(f, replyTimeout)
+		if err == ErrTimeout {
+			return colorLevelGuess(f) == ColorTrue, false, nil
+		}
+		ok = colors == ColorTrue || colorLevelGuess(f) == ColorTrue
+	case FeatureSixel:
+		var da string
+		da, err = DeviceAttributes(f, replyTimeout)
+		ok = hasParam(da, "4")
+	default:
+		return false, false, errors.New("feature: " + strconv.Itoa(int(feat)) + " not a valid Feature")
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return ok, true, nil
+}
+
+// modeSupported asks the terminal f about the DEC private mode using DECRQM.
+// The ESC[?mode;Ps$y reply has Ps 0 for an unknown mode, 1 or 2 for set or reset and 3 or 4 for
+// permanently set or reset, a mode that can't be set isn't supported.
+func modeSupported(f *os.File, mode int) (bool, error) {
+	m := strconv.Itoa(mode)
+	reply, err := Query(f, CSI+"?"+m+"$p"+CSI+"c", 'c', replyTimeout)
+	if err != nil {
+		return false, err
+	}
+	body, ok := replyBody(reply, CSI+"?"+m+";")
+	if !ok {
+		// Only the device attributes came back.
+		return false, nil
+	}
+	ps, _, ok := bytes.Cut(body, []byte("$y"))
+	if !ok {
+		return false, errors.New("DECRQM reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	switch string(ps) {
+	case "1", "2", "3":
+		return true, nil
+	}
+	return false, nil
+}
+
+// hasParam returns true if param is one of the ; separated params.
+func hasParam(params, param string) bool {
+	for _, p := range strings.Split(params, ";") {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
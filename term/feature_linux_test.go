@@ -0,0 +1,120 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSupportsFeature tests probing the features from the replies of the terminal.
+func TestSupportsFeature(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORTERM", "")
+	tsts := []struct {
+		feat           Feature
+		request, reply string
+		want           bool
+	}{
+		{FeatureBracketedPaste, "\x1b[?2004$p\x1b[c", "\x1b[?2004;2$y\x1b[?64;22c", true},
+		{FeatureBracketedPaste, "\x1b[?2004$p\x1b[c", "\x1b[?2004;0$y\x1b[?64;22c", false},
+		{FeatureBracketedPaste, "\x1b[?2004$p\x1b[c", "\x1b[?64;22c", false},
+		{FeatureMouseSGR, "\x1b[?1006$p\x1b[c", "\x1b[?1006;1$y\x1b[?1;2c", true},
+		{FeatureMouseSGR, "\x1b[?1006$p\x1b[c", "\x1b[?1006;4$y\x1b[?1;2c", false},
+		{FeatureSixel, "\x1b[c", "\x1b[?64;4;22c", true},
+		{FeatureSixel, "\x1b[c", "\x1b[?64;22;44c", false},
+		{FeatureTrueColor, colorsRequest, "\x1bP1+r636f6c6f7273=3136373737323136\x1b\\", true},
+		{FeatureTrueColor, colorsRequest, "\x1bP1+r636f6c6f7273=323536\x1b\\", false},
+	}
+	for _, tst := range tsts {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		defer p.Close()
+		out := readMaster(p)
+		answer := func() {
+			out.reset()
+			go func() {
+				if strings.Contains(out.waitFor(tst.request), tst.request) {
+					p.Master.Write([]byte(tst.reply))
+				}
+			}()
+		}
+		answer()
+		if got, err := SupportsFeature(p.Slave, tst.feat); err != nil || got != tst.want {
+			t.Errorf("SupportsFeature(%v) reply: %q got: %t,%v want: %t,<nil>", tst.feat, tst.reply, got, err, tst.want)
+		}
+		c := NewController(p.Slave)
+		answer()
+		if got, err := c.SupportsFeature(tst.feat); err != nil || got != tst.want {
+			t.Errorf("Controller.SupportsFeature(%v) reply: %q got: %t,%v want: %t,<nil>", tst.feat, tst.reply, got, err, tst.want)
+		}
+		// Asked again nothing replies, the answer has to come from the Controller.
+		start := time.Now()
+		if got, err := c.SupportsFeature(tst.feat); err != nil || got != tst.want {
+			t.Errorf("SupportsFeature(%v) cached got: %t,%v want: %t,<nil>", tst.feat, got, err, tst.want)
+		}
+		if time.Since(start) > replyTimeout/2 {
+			t.Errorf("SupportsFeature(%v) cached took: %v", tst.feat, time.Since(start))
+		}
+	}
+}
+
+// TestSupportsFeatureTimeout tests a terminal not replying to the probes.
+func TestSupportsFeatureTimeout(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	if _, err := SupportsFeature(p.Slave, FeatureBracketedPaste); err != ErrTimeout {
+		t.Errorf("SupportsFeature got: %v want: %v", err, ErrTimeout)
+	}
+	if _, err := SupportsFeature(p.Slave, Feature(7)); err == nil {
+		t.Errorf("SupportsFeature(Feature(7)) got: <nil> want: error")
+	}
+	if got, want := Feature(7).String(), "Feature(7)"; got != want {
+		t.Errorf("Feature(7).String() got: %q want: %q", got, want)
+	}
+}
+
+// TestSupportsFeatureNotCached tests a probe the terminal didn't answer being asked again by a Controller.
+func TestSupportsFeatureNotCached(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORTERM", "")
+	for _, tst := range []struct {
+		feat           Feature
+		request, reply string
+		fail           bool
+	}{
+		{FeatureTrueColor, colorsRequest, "\x1bP1+r636f6c6f7273=3136373737323136\x1b\\", false},
+		{FeatureBracketedPaste, "\x1b[?2004$p\x1b[c", "\x1b[?2004;2$y\x1b[?64;22c", true},
+	} {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		defer p.Close()
+		c := NewController(p.Slave)
+		out := readMaster(p)
+		// Nothing replies, the truecolor guess from the environment is false for TERM=xterm.
+		got, err := c.SupportsFeature(tst.feat)
+		if got || (err != nil) != tst.fail {
+			t.Errorf("SupportsFeature(%v) with no reply got: %t,%v", tst.feat, got, err)
+		}
+		out.reset()
+		go func() {
+			if strings.Contains(out.waitFor(tst.request), tst.request) {
+				p.Master.Write([]byte(tst.reply))
+			}
+		}()
+		if got, err := c.SupportsFeature(tst.feat); err != nil || !got {
+			t.Errorf("Controller.SupportsFeature(%v) answered after a timeout got: %t,%v want: true,<nil>", tst.feat, got, err)
+		}
+	}
+}
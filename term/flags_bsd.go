@@ -0,0 +1,69 @@
+//go:build darwin || freebsd
+
+package term
+
+// INPUT handling terminal flags, the values used by Darwin and FreeBSD.
+// IUTF8 is set per platform in termios_darwin.go and termios_freebsd.go.
+// see 'man stty' for further info about most of the constants
+const (
+	IGNBRK  = 0x00000001 // IGNBRK ignore break characters
+	BRKINT  = 0x00000002 // BRKINT Break genereates an interrupt signal
+	IGNPAR  = 0x00000004 // IGNPAR Ignore characters with parity errors
+	PARMRK  = 0x00000008 // PARMRK Mark parity errors byte{ff,0}
+	INPCK   = 0x00000010 // INPCK enable parity checking
+	ISTRIP  = 0x00000020 // ISTRIP Clear 8th bit of input characters
+	INLCR   = 0x00000040 // INLCR Translate LF => CR
+	IGNCR   = 0x00000080 // IGNCR Ignore Carriage Return
+	ICRNL   = 0x00000100 // ICRNL Translate CR => NL
+	IXON    = 0x00000200 // IXON Enable flow control
+	IXOFF   = 0x00000400 // IXOFF start sending start/stop chars
+	IXANY   = 0x00000800 // IXANY let any char restart input
+	IMAXBEL = 0x00002000 // IMAXBEL Sound the bell and skip flushing input buffer
+	IUCLC   = 0          // IUCLC no such flag on the BSDs
+)
+
+// OUTPUT treatment terminal flags, OFILL and OFDEL are set per platform.
+const (
+	OPOST  = 0x00000001 // OPOST post process output
+	ONLCR  = 0x00000002 // ONLCR Map NL -> CR-NL
+	ONOEOT = 0x00000008 // ONOEOT discard EOT chars (^D) on output
+	OCRNL  = 0x00000010 // OCRNL Map CR -> NL
+	ONOCR  = 0x00000020 // ONOCR No CR at col 0
+	ONLRET = 0x00000040 // ONLRET NL also do CR
+	OLCUC  = 0          // OLCUC no such flag on the BSDs
+)
+
+// TERM control modes.
+const (
+	CSIZE  = 0x00000300 // CSIZE used as mask when setting character size
+	CS5    = 0x00000000 // CS5 char size 5bits
+	CS6    = 0x00000100 // CS6 char size 6bits
+	CS7    = 0x00000200 // CS7 char size 7bits
+	CS8    = 0x00000300 // CS8 char size 8bits
+	CSTOPB = 0x00000400 // CSTOPB two stop bits
+	CREAD  = 0x00000800 // CREAD enable input
+	PARENB = 0x00001000 // PARENB generate and expect parity bit
+	PARODD = 0x00002000 // PARODD set odd parity
+	HUPCL  = 0x00004000 // HUPCL send HUP when last process closes term
+	CLOCAL = 0x00008000 // CLOCAL no modem control signals
+)
+
+// TERM modes
+const (
+	ECHOKE  = 0x00000001 // ECHOKE kill all line considering ECHOPRT and ECHOE flags
+	ECHOE   = 0x00000002 // ECHOE erase => BS - SPACE - BS
+	ECHOK   = 0x00000004 // ECHOK add newline after kill char
+	ECHO    = 0x00000008 // ECHO echo input characters
+	ECHONL  = 0x00000010 // ECHONL echo NL even without other characters
+	ECHOPRT = 0x00000020 // ECHOPRT will print erased characters between \ /
+	ECHOCTL = 0x00000040 // ECHOCTL will echo control characters as ^c
+	ISIG    = 0x00000080 // ISIG enable Interrupt,quit and suspend chars
+	ICANON  = 0x00000100 // ICANON enable erase,kill ,werase and rprnt chars
+	IEXTEN  = 0x00000400 // IEXTEN enable non POSIX special characters
+	EXTPROC = 0x00000800 // EXTPROC external processing, the line editing is done on the other end
+	TOSTOP  = 0x00400000 // TOSTOP stop BG jobs trying to write to term
+	FLUSHO  = 0x00800000 // FLUSHO output being flushed, toggled by the discard char
+	PENDIN  = 0x20000000 // PENDIN retype pending input at next read or input char
+	NOFLSH  = 0x80000000 // NOFLSH no flush after interrupt and kill characters
+	XCASE   = 0          // XCASE no such flag on the BSDs
+)
@@ -0,0 +1,70 @@
+//go:build !darwin && !freebsd
+
+package term
+
+// INPUT handling terminal flags, the values used by Linux, the BSDs have their own in flags_bsd.go.
+// see 'man stty' for further info about most of the constants
+const (
+	IGNBRK  = 0000001 // IGNBRK ignore break characters
+	BRKINT  = 0000002 // BRKINT Break genereates an interrupt signal
+	IGNPAR  = 0000004 // IGNPAR Ignore characters with parity errors
+	PARMRK  = 0000010 // PARMRK Mark parity errors byte{ff,0}
+	INPCK   = 0000020 // INPCK enable parity checking
+	ISTRIP  = 0000040 // ISTRIP Clear 8th bit of input characters
+	INLCR   = 0000100 // INLCR Translate LF => CR
+	IGNCR   = 0000200 // IGNCR Ignore Carriage Return
+	ICRNL   = 0000400 // ICRNL Translate CR => NL
+	IUCLC   = 0001000 // IUCLC Translate uppercase to lowercase
+	IXON    = 0002000 // IXON Enable flow control
+	IXANY   = 0004000 // IXANY let any char restart input
+	IXOFF   = 0010000 // IXOFF start sending start/stop chars
+	IMAXBEL = 0020000 // IMAXBEL Sound the bell and skip flushing input buffer
+	IUTF8   = 0040000 // IUTF8 assume input being utf-8
+)
+
+// OUTPUT treatment terminal flags
+const (
+	OPOST  = 0000001 // OPOST post process output
+	OLCUC  = 0000002 // OLCUC translate lower case to upper case
+	ONLCR  = 0000004 // ONLCR Map NL -> CR-NL
+	OCRNL  = 0000010 // OCRNL Map CR -> NL
+	ONOCR  = 0000020 // ONOCR No CR at col 0
+	ONLRET = 0000040 // ONLRET NL also do CR
+	OFILL  = 0000100 // OFILL Fillchar for delay
+	OFDEL  = 0000200 // OFDEL use delete instead of null
+)
+
+// TERM control modes.
+const (
+	CSIZE  = 0000060 // CSIZE used as mask when setting character size
+	CS5    = 0000000 // CS5 char size 5bits
+	CS6    = 0000020 // CS6 char size 6bits
+	CS7    = 0000040 // CS7 char size 7bits
+	CS8    = 0000060 // CS8 char size 8bits
+	CSTOPB = 0000100 // CSTOPB two stop bits
+	CREAD  = 0000200 // CREAD enable input
+	PARENB = 0000400 // PARENB generate and expect parity bit
+	PARODD = 0001000 // PARODD set odd parity
+	HUPCL  = 0002000 // HUPCL send HUP when last process closes term
+	CLOCAL = 0004000 // CLOCAL no modem control signals
+)
+
+// TERM modes
+const (
+	ISIG    = 0000001 // ISIG enable Interrupt,quit and suspend chars
+	ICANON  = 0000002 // ICANON enable erase,kill ,werase and rprnt chars
+	XCASE   = 0000004 // XCASE preceedes all uppercase chars with '\'
+	ECHO    = 0000010 // ECHO echo input characters
+	ECHOE   = 0000020 // ECHOE erase => BS - SPACE - BS
+	ECHOK   = 0000040 // ECHOK add newline after kill char
+	ECHONL  = 0000100 // ECHONL echo NL even without other characters
+	NOFLSH  = 0000200 // NOFLSH no flush after interrupt and kill characters
+	TOSTOP  = 0000400 // TOSTOP stop BG jobs trying to write to term
+	ECHOCTL = 0001000 // ECHOCTL will echo control characters as ^c
+	ECHOPRT = 0002000 // ECHOPRT will print erased characters between \ /
+	ECHOKE  = 0004000 // ECHOKE kill all line considering ECHOPRT and ECHOE flags
+	FLUSHO  = 0010000 // FLUSHO output being flushed, toggled by the discard char
+	PENDIN  = 0040000 // PENDIN retype pending input at next read or input char
+	IEXTEN  = 0100000 // IEXTEN enable non POSIX special characters
+	EXTPROC = 0200000 // EXTPROC external processing, the line editing is done on the other end
+)
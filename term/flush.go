@@ -0,0 +1,53 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Queues for Flush.
+const (
+	FlushInput  = iota // FlushInput data received but not read
+	FlushOutput        // FlushOutput data written but not transmitted
+	FlushBoth          // FlushBoth both the input and output queue
+)
+
+// Flush discards the data in the which queue of the terminal f.
+func Flush(f *os.File, which int) error {
+	if which < FlushInput || which > FlushBoth {
+		return errors.New("which: " + strconv.Itoa(which) + " not a valid flush queue")
+	}
+	return tcflush(f, which)
+}
+
+// Drain waits until all output written to the terminal f has been transmitted.
+func Drain(f *os.File) error {
+	return tcdrain(f)
+}
+
+// defaultBreak is how long SendBreak holds the break for a zero duration, same as tcsendbreak(3).
+const defaultBreak = 250 * time.Millisecond
+
+// SendBreak holds the line of terminal f in the break state for durationMs milliseconds.
+// A zero durationMs sends a break of 250ms.
+// The break is asserted and cleared with separate IOCTLs so the duration works on all platforms.
+func SendBreak(f *os.File, durationMs int) error {
+	if durationMs < 0 {
+		return errors.New("durationMs: " + strconv.Itoa(durationMs) + " not a valid duration")
+	}
+	d := time.Duration(durationMs) * time.Millisecond
+	if d == 0 {
+		d = defaultBreak
+	}
+	if err := setBreak(f, true); err != nil {
+		return err
+	}
+	time.Sleep(d)
+	return setBreak(f, false)
+}
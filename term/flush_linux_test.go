@@ -0,0 +1,100 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFlush tests discarding unread input of a terminal.
+func TestFlush(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	if _, err := p.Master.Write([]byte("stale\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// Give the line discipline time to queue the input.
+	time.Sleep(50 * time.Millisecond)
+	if err := Flush(p.Slave, FlushInput); err != nil {
+		t.Fatalf("Flush(p.Slave, FlushInput) failed: %v", err)
+	}
+	if _, err := p.Master.Write([]byte("fresh\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	line, err := GetLine("", p.Slave, make([]byte, 64))
+	if err != nil {
+		t.Fatalf("GetLine failed: %v", err)
+	}
+	if got, want := string(line), "fresh"; got != want {
+		t.Errorf("GetLine got: %q want: %q", got, want)
+	}
+	for _, which := range []int{FlushOutput, FlushBoth} {
+		if err := Flush(p.Slave, which); err != nil {
+			t.Errorf("Flush(p.Slave, %d) failed: %v", which, err)
+		}
+	}
+	if err := Flush(p.Slave, FlushBoth+1); err == nil {
+		t.Errorf("Flush(p.Slave, %d) got: <nil> want: invalid queue error", FlushBoth+1)
+	}
+}
+
+// TestDrain tests waiting for the output of a terminal to be transmitted.
+func TestDrain(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	out := readMaster(p)
+	if _, err := p.Slave.Write([]byte("drained")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := Drain(p.Slave); err != nil {
+		t.Fatalf("Drain(p.Slave) failed: %v", err)
+	}
+	if got := out.waitFor("drained"); got != "drained" {
+		t.Errorf("Drain output got: %q want: %q", got, "drained")
+	}
+	nf, err := donormfile("TestDrain")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestDrain\") failed: %v", err)
+	}
+	defer nf.Close()
+	if err := Drain(nf); err == nil {
+		t.Errorf("Drain(%q) got: <nil> want: not a tty error", nf.Name())
+	}
+}
+
+// TestSendBreak tests holding a terminal in the break state.
+func TestSendBreak(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	start := time.Now()
+	if err := SendBreak(p.Slave, 20); err != nil {
+		t.Fatalf("SendBreak(p.Slave, 20) failed: %v", err)
+	}
+	if got := time.Since(start); got < 20*time.Millisecond {
+		t.Errorf("SendBreak took: %v want: >= 20ms", got)
+	}
+	if err := SendBreak(p.Slave, -1); err == nil {
+		t.Errorf("SendBreak(p.Slave, -1) got: <nil> want: invalid duration error")
+	}
+	nf, err := donormfile("TestSendBreak")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestSendBreak\") failed: %v", err)
+	}
+	defer nf.Close()
+	if err := SendBreak(nf, 1); err == nil {
+		t.Errorf("SendBreak(%q) got: <nil> want: not a tty error", nf.Name())
+	}
+}
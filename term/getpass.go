@@ -0,0 +1,387 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+	"unicode/utf8"
+)
+
+// GetPass reads password from a TTY with no echo.
+// Backspace (0x7f or 0x08) removes the last byte read and Ctrl-U (0x15) clears everything read so far.
+// Ctrl-D (0x04) or EOF at the start of the line returns an empty slice and io.EOF so a cancelled
+// prompt can be told apart from an empty password.
+// Input is taken as UTF-8, backspace removes a whole rune and a rune not fitting in pbuf is an
+// overflow, never stored in part. The returned slice is the raw bytes typed, it isn't validated.
+func GetPass(prompt string, f *os.File, pbuf []byte) ([]byte, error) {
+	return GetPassTo(f, f, prompt, pbuf)
+}
+
+// GetPassTo reads password from the TTY in with no echo writing the prompt to out.
+// Use it to keep the prompt out of redirected output, eg. reading from os.Stdin and prompting on os.Stderr.
+// Editing and EOF are handled as in GetPass.
+func GetPassTo(in, out *os.File, prompt string, pbuf []byte) ([]byte, error) {
+	return getPass(context.Background(), prompt, NewTerminal(in), out, pbuf, readMode{clear: ECHO})
+}
+
+// GetPassTerminal reads password from the Terminal t with no echo, same as GetPass.
+// Pass a MockTTY to test password prompts without a real terminal.
+func GetPassTerminal(prompt string, t Terminal, pbuf []byte) ([]byte, error) {
+	return getPass(context.Background(), prompt, t, t, pbuf, readMode{clear: ECHO})
+}
+
+// GetPassMasked reads password from a TTY echoing mask for every rune typed.
+// Backspace and Ctrl-U are handled like in GetPass also erasing the masks from the terminal.
+// The terminating newline is not echoed.
+func GetPassMasked(prompt string, f *os.File, pbuf []byte, mask byte) ([]byte, error) {
+	return getPass(context.Background(), prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO | ICANON, mask: mask})
+}
+
+// GetPassFlags reads password from a TTY turning off the Lflag bits in clear for the read, eg.
+// ECHO|ICANON|ISIG to get the bytes as typed with Ctrl-C read as part of the password.
+// ECHO is always turned off, clear 0 is the same as GetPass. With ICANON cleared the editing
+// keys are handled while reading as in GetPassMasked, with no mask echoed.
+func GetPassFlags(prompt string, f *os.File, pbuf []byte, clear uint32) ([]byte, error) {
+	return getPass(context.Background(), prompt, NewTerminal(f), f, pbuf, readMode{clear: clear | ECHO})
+}
+
+// GetPassFlushed reads password from a TTY with no echo same as GetPass, discarding anything typed
+// before the prompt is shown so keys typed ahead don't end up in the password.
+func GetPassFlushed(prompt string, f *os.File, pbuf []byte) ([]byte, error) {
+	return getPass(context.Background(), prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO, flush: true})
+}
+
+// GetPassDelim reads password from a TTY with no echo ending the input on delim only.
+// Everything else is stored as read, including carriage return, newline and the editing keys,
+// for secrets sent with a specific framing eg. over a serial line. The terminal is put in
+// non-canonical mode with the input CR/NL translations turned off for the read.
+func GetPassDelim(prompt string, f *os.File, pbuf []byte, delim byte) ([]byte, error) {
+	return getPass(context.Background(), prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO | ICANON, delim: delim, delimOnly: true})
+}
+
+// readPasswordMax is the longest password ReadPassword reads, to not grow the buffer forever.
+const readPasswordMax = 64 << 10
+
+// ReadPassword reads password from a TTY with no echo returning it as a string.
+// Unlike GetPass the buffer is allocated here, starting small and doubling in size as needed up to
+// readPasswordMax bytes, longer passwords give an error. The buffers are cleared once done with,
+// the returned string can't be. Use GetPass to control the allocation and clearing.
+// The terminal is put in non-canonical mode so the line isn't cut at the kernel's line length limit,
+// the erase and kill characters of the terminal are handled while reading as in GetPassMasked.
+// EOF and Ctrl-D on an empty line give "" and io.EOF.
+func ReadPassword(prompt string, f *os.File) (string, error) {
+	pbuf := make([]byte, 64)
+	pass, err := getPass(context.Background(), prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO | ICANON, max: readPasswordMax})
+	if err != nil {
+		clearbuf(pbuf)
+		return "", err
+	}
+	s := string(pass)
+	clearbuf(pass[:cap(pass)])
+	return s, nil
+}
+
+// passMax is the longest password read by the functions allocating their own buffer.
+const passMax = 4096
+
+// GetPassConfirm reads a password from a TTY with no echo and then reads it again after confirmPrompt.
+// The two are compared in constant time, if they differ both are cleared and ErrMismatch returned.
+// The buffers are allocated here, passwords up to passMax bytes can be read.
+func GetPassConfirm(prompt, confirmPrompt string, f *os.File) ([]byte, error) {
+	pbuf := make([]byte, passMax)
+	pass, err := GetPass(prompt, f, pbuf)
+	if err != nil {
+		return nil, err
+	}
+	cbuf := make([]byte, passMax)
+	defer clearbuf(cbuf)
+	confirm, err := GetPass(confirmPrompt, f, cbuf)
+	if err != nil {
+		clearbuf(pbuf)
+		return nil, err
+	}
+	if !SecureCompare(pass, confirm) {
+		clearbuf(pbuf)
+		return nil, ErrMismatch
+	}
+	return pass, nil
+}
+
+// pollInterval is how long a read waits for input before checking if the context got cancelled.
+const pollInterval = 100 * time.Millisecond
+
+// GetPassContext reads password from a TTY with no echo aborting when ctx is cancelled.
+// The read loop never blocks for longer than pollInterval. For files using the Go runtime poller,
+// eg. PTYs from OpenPTY, a read deadline is set. For blocking files like os.Stdin the terminal is
+// put in non-canonical mode with VMIN=0 and VTIME set so the read times out in the kernel.
+// On cancellation the partially read password is cleared and ctx.Err() returned.
+// The terminal attributes are restored in all cases.
+func GetPassContext(ctx context.Context, prompt string, f *os.File, pbuf []byte) ([]byte, error) {
+	return getPass(ctx, prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO | ICANON})
+}
+
+// TimeoutError is returned by GetPassTimeout when no full line was read in time.
+type TimeoutError struct {
+	After time.Duration // After the timeout that passed
+}
+
+// Error implements the error interface.
+func (e *TimeoutError) Error() string {
+	return "timed out after " + e.After.String()
+}
+
+// Timeout reports the error is a timeout, same as the net.Error interface.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// Unwrap makes errors.Is(err, ErrTimeout) true for a *TimeoutError.
+func (e *TimeoutError) Unwrap() error {
+	return ErrTimeout
+}
+
+// GetPassTimeout reads password from a TTY with no echo giving up after d.
+// The reads are bounded the same way as in GetPassContext. If d passes before a full line is read
+// a *TimeoutError is returned, the partially read password is cleared and the terminal restored.
+func GetPassTimeout(prompt string, f *os.File, pbuf []byte, d time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	pass, err := getPass(ctx, prompt, NewTerminal(f), f, pbuf, readMode{clear: ECHO | ICANON})
+	if err == context.DeadlineExceeded {
+		return nil, &TimeoutError{After: d}
+	}
+	return pass, err
+}
+
+// readMode is how getPass and readLine handle the input.
+type readMode struct {
+	clear     uint32 // clear the Lflag bits turned off while reading
+	mask      byte   // mask echoed for every rune typed, 0 for none
+	delim     byte   // delim the only byte ending the input if delimOnly is set
+	delimOnly bool   // delimOnly end on delim only, otherwise on newline and carriage return
+	max       int    // max the size pbuf can grow to, it never grows if not bigger than pbuf
+	flush     bool   // flush the input typed ahead before the prompt is written
+	erase     byte   // erase the VERASE char of the terminal removing a rune, 0 for none
+	kill      byte   // kill the VKILL char of the terminal removing the line, 0 for none
+}
+
+// fit makes room for n bytes after the first i in pbuf growing it, doubling the size up to mode.max.
+// The old buffer is cleared once copied. It returns false if there's no room for n more bytes.
+func (mode readMode) fit(pbuf *[]byte, i, n int) bool {
+	for len(*pbuf)-i < n {
+		if len(*pbuf) >= mode.max {
+			return false
+		}
+		nbuf := make([]byte, min(max(2*len(*pbuf), 1), mode.max))
+		copy(nbuf, (*pbuf)[:i])
+		clearbuf(*pbuf)
+		*pbuf = nbuf
+	}
+	return true
+}
+
+// getPass turns off the Lflag bits in mode.clear on in and reads a password into pbuf, the prompt and masks go to out.
+// With ICANON cleared the bytes are handed to us as they're typed, this is needed for mask to be
+// echoed back for every byte read and for the reads to be interrupted when ctx can be cancelled.
+// With delimOnly the input CR and NL translations are turned off too so they're read as sent.
+func getPass(ctx context.Context, prompt string, in Terminal, out io.Writer, pbuf []byte, mode readMode) ([]byte, error) {
+	t, err := in.Attr()
+	if err != nil {
+		return nil, err
+	}
+	defer in.SetAttr(&t)
+	noecho := t
+	noecho.Lflag = noecho.Lflag &^ mode.clear
+	if mode.delimOnly {
+		noecho.Iflag &^= ICRNL | INLCR | IGNCR
+	}
+	if mode.clear&ICANON != 0 {
+		// Without the line discipline doing the editing readLine does it.
+		mode.erase = t.Cc[VERASE]
+		mode.kill = t.Cc[VKILL]
+		noecho.Cc[VMIN] = 1
+		noecho.Cc[VTIME] = 0
+		if ctx.Done() != nil {
+			noecho.Cc[VMIN] = 0
+			noecho.Cc[VTIME] = byte(pollInterval / (100 * time.Millisecond))
+		}
+	}
+	if err := in.SetAttr(&noecho); err != nil {
+		return nil, err
+	}
+	if ft, ok := in.(fileTerminal); ok && mode.flush {
+		if err := Flush(ft.File, FlushInput); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := out.Write([]byte(prompt)); err != nil {
+		return nil, err
+	}
+	return readLine(ctx, in, out, pbuf, mode)
+}
+
+// GetLine reads a line from a TTY with echo.
+// The terminal is put in canonical mode for the read so the line can be edited as usual.
+// The trailing newline is not included in the returned line.
+func GetLine(prompt string, f *os.File, buf []byte) ([]byte, error) {
+	t, err := Attr(f)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Set(f)
+	cooked := t
+	cooked.Lflag |= ICANON | ECHO
+	if err := cooked.Set(f); err != nil {
+		return nil, err
+	}
+	if _, err := f.Write([]byte(prompt)); err != nil {
+		return nil, err
+	}
+	return readLine(context.Background(), NewTerminal(f), f, buf, readMode{})
+}
+
+// readLine reads from f into pbuf byte by byte until a newline or carriage return, or mode.delim
+// with delimOnly set. Masks are echoed to out.
+// The reads are interrupted every pollInterval to check ctx if it can be cancelled.
+// EOF or Ctrl-D before anything was typed gives an empty slice and io.EOF, EOF in the middle of
+// the line io.ErrUnexpectedEOF. On any error what was read so far is cleared.
+func readLine(ctx context.Context, f Terminal, out io.Writer, pbuf []byte, mode readMode) ([]byte, error) {
+	mask := mode.mask
+	poll := ctx.Done() != nil
+	if poll {
+		defer f.SetReadDeadline(time.Time{})
+	}
+	b := make([]byte, 1, 1)
+	i := 0
+	for mode.fit(&pbuf, i, 1) {
+		if err := ctx.Err(); err != nil {
+			clearbuf(pbuf[:i])
+			return nil, err
+		}
+		if poll {
+			f.SetReadDeadline(time.Now().Add(pollInterval))
+		}
+		n, err := f.Read(b)
+		if poll && pollTimeout(err) {
+			continue
+		}
+		if errors.Is(err, syscall.EINTR) {
+			// Interrupted by a signal, try again.
+			continue
+		}
+		if err != nil {
+			clearbuf(pbuf[:i])
+			if err == io.EOF {
+				if i == 0 {
+					return pbuf[:0], io.EOF
+				}
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if n == 0 {
+			// Nothing read without an error, don't take the zero in b as typed.
+			continue
+		}
+		switch {
+		case mode.delimOnly && b[0] == mode.delim:
+			b[0] = 0
+			return pbuf[:i], nil
+		case mode.delimOnly:
+			// Only delim ends the input, newline and carriage return are stored.
+		case b[0] == '\n' || b[0] == '\r':
+			return pbuf[:i], nil
+		case b[0] == 0x04:
+			// Ctrl-D, EOF at the start of the line, ignored otherwise.
+			b[0] = 0
+			if i == 0 {
+				return pbuf[:0], io.EOF
+			}
+			continue
+		case b[0] == 0x7f || b[0] == 0x08 || (mode.erase != 0 && b[0] == mode.erase):
+			// Backspace or the erase char, remove the last rune.
+			if i > 0 {
+				_, n := utf8.DecodeLastRune(pbuf[:i])
+				clearbuf(pbuf[i-n : i])
+				i -= n
+				if mask != 0 {
+					out.Write([]byte("\b \b"))
+				}
+			}
+			b[0] = 0
+			continue
+		case b[0] == 0x15 || (mode.kill != 0 && b[0] == mode.kill):
+			// Ctrl-U or the kill char, throw away everything typed so far.
+			if mask != 0 {
+				out.Write(bytes.Repeat([]byte("\b \b"), utf8.RuneCount(pbuf[:i])))
+			}
+			clearbuf(pbuf[:i])
+			i = 0
+			b[0] = 0
+			continue
+		}
+		if !mode.fit(&pbuf, i, runeLen(b[0])) {
+			// The rune this starts won't fit, don't keep half of it.
+			b[0] = 0
+			break
+		}
+		pbuf[i] = b[0]
+		if mask != 0 && utf8.RuneStart(b[0]) {
+			out.Write([]byte{mask})
+		}
+		b[0] = 0
+		i++
+	}
+	clearbuf(pbuf[:i])
+	return nil, errors.New("ran out of bufferspace")
+}
+
+// runeLen returns the length of the UTF-8 sequence started by c.
+// Continuation and invalid bytes count as one byte, the same as utf8.DecodeRune does.
+func runeLen(c byte) int {
+	switch {
+	case c < 0xc0:
+		return 1
+	case c < 0xe0:
+		return 2
+	case c < 0xf0:
+		return 3
+	case c < 0xf8:
+		return utf8.UTFMax
+	}
+	return 1
+}
+
+// pollTimeout checks if err is from a read timing out.
+// With VMIN=0 a timed out read returns no data, which os.File reports as io.EOF.
+func pollTimeout(err error) bool {
+	return err == io.EOF || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// clearbuf clears out the buffer incase we couldn't read the full password.
+func clearbuf(b []byte) {
+	Zero(b)
+}
+
+// Zero overwrites b with zeros, use it to get rid of a password once done with it.
+func Zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// SecureCompare returns true if a and b are equal.
+// The time taken only depends on the length of the slices, not their content, so it's safe to
+// use for comparing passwords.
+func SecureCompare(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
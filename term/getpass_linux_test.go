@@ -0,0 +1,686 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// masterOutput collects everything read from a PTY master.
+type masterOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// readMaster starts reading everything written to the slave of p.
+func readMaster(p *PTY) *masterOutput {
+	out := &masterOutput{}
+	go func() {
+		b := make([]byte, 512)
+		for {
+			nr, err := p.Master.Read(b)
+			if err != nil {
+				return
+			}
+			out.mu.Lock()
+			out.buf.Write(b[:nr])
+			out.mu.Unlock()
+		}
+	}()
+	return out
+}
+
+// waitFor waits for want to show up in the collected output and returns the output so far.
+func (o *masterOutput) waitFor(want string) string {
+	var res string
+	for i := 0; i < 200; i++ {
+		o.mu.Lock()
+		res = o.buf.String()
+		o.mu.Unlock()
+		if strings.Contains(res, want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return res
+}
+
+// reset throws away the collected output.
+func (o *masterOutput) reset() {
+	o.mu.Lock()
+	o.buf.Reset()
+	o.mu.Unlock()
+}
+
+// TestGetPassMasked tests reading a password echoing a mask character.
+func TestGetPassMasked(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	go func() {
+		if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+			return
+		}
+		tty.Master.Write([]byte("abc\x7fd\x08e\n"))
+	}()
+	buf := make([]byte, 512)
+	pass, err := GetPassMasked("Pass:", tty.Slave, buf, '*')
+	if err != nil {
+		t.Fatalf("GetPassMasked(\"Pass:\",tty.Slave,buf,'*') failed: %v", err)
+	}
+	if string(pass) != "abe" {
+		t.Errorf("GetPassMasked got: %q want: %q", pass, "abe")
+	}
+	want := "Pass:***\b \b*\b \b*"
+	if res := out.waitFor(want); res != want {
+		t.Errorf("GetPassMasked echoed: %q want: %q", res, want)
+	}
+	// Backspace on an empty buffer should not erase the prompt.
+	out.reset()
+	go func() {
+		if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+			return
+		}
+		tty.Master.Write([]byte("\x7fx\n"))
+	}()
+	if pass, err = GetPassMasked("Pass:", tty.Slave, buf, '*'); err != nil {
+		t.Fatalf("GetPassMasked(\"Pass:\",tty.Slave,buf,'*') failed: %v", err)
+	}
+	if string(pass) != "x" {
+		t.Errorf("GetPassMasked got: %q want: %q", pass, "x")
+	}
+	if res := out.waitFor("Pass:*"); res != "Pass:*" {
+		t.Errorf("GetPassMasked echoed: %q want: %q", res, "Pass:*")
+	}
+}
+
+// TestGetPassEditing tests backspace and Ctrl-U handling in GetPass.
+func TestGetPassEditing(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	// Turning off canonical mode so the line discipline won't handle the editing for us.
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	if err := tr.SetCbreak(tty.Slave); err != nil {
+		t.Fatalf("SetCbreak(tty.Slave) failed: %v", err)
+	}
+	out := readMaster(tty)
+	tsts := []struct {
+		in   string
+		want string
+	}{
+		{"secrex\x7ft\n", "secret"},
+		{"\x7f\x08pass\n", "pass"},
+		{"wrong\x15right\n", "right"},
+		{"ab\x15\x7fc\n", "c"},
+	}
+	buf := make([]byte, 16)
+	for _, tst := range tsts {
+		out.reset()
+		go func(in string) {
+			if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+				return
+			}
+			tty.Master.Write([]byte(in))
+		}(tst.in)
+		pass, err := GetPass("Pass:", tty.Slave, buf)
+		if err != nil {
+			t.Errorf("GetPass(%q) failed: %v", tst.in, err)
+			continue
+		}
+		if string(pass) != tst.want {
+			t.Errorf("GetPass(%q) got: %q want: %q", tst.in, pass, tst.want)
+		}
+		// Whatever was removed should be zeroed out.
+		for _, c := range buf[len(pass):] {
+			if c != 0 {
+				t.Errorf("GetPass(%q) left removed bytes in buffer: %q", tst.in, buf)
+				break
+			}
+		}
+		clearbuf(buf)
+	}
+	out.reset()
+	go func() {
+		if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+			return
+		}
+		tty.Master.Write([]byte("abc\x15d\n"))
+	}()
+	if _, err := GetPassMasked("Pass:", tty.Slave, buf, '*'); err != nil {
+		t.Fatalf("GetPassMasked failed: %v", err)
+	}
+	want := "Pass:***\b \b\b \b\b \b*"
+	if res := out.waitFor(want); res != want {
+		t.Errorf("GetPassMasked echoed: %q want: %q", res, want)
+	}
+}
+
+// TestGetPassContext tests cancelling a password read.
+func TestGetPassContext(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	backup, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	out := readMaster(tty)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+			return
+		}
+		tty.Master.Write([]byte("abc"))
+		time.Sleep(3 * pollInterval)
+		cancel()
+	}()
+	buf := make([]byte, 16)
+	if _, err := GetPassContext(ctx, "Pass:", tty.Slave, buf); err != context.Canceled {
+		t.Errorf("GetPassContext got: %v want: %v", err, context.Canceled)
+	}
+	for _, c := range buf {
+		if c != 0 {
+			t.Errorf("GetPassContext should clear buffer on cancel got: %q", buf)
+			break
+		}
+	}
+	if got, _ := Attr(tty.Slave); got != backup {
+		t.Errorf("GetPassContext did not restore attributes got: %v want: %v", got, backup)
+	}
+	// Not cancelled.
+	out.reset()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		if res := out.waitFor("Pass:"); !strings.Contains(res, "Pass:") {
+			return
+		}
+		tty.Master.Write([]byte("ab"))
+		time.Sleep(2 * pollInterval)
+		tty.Master.Write([]byte("c\n"))
+	}()
+	pass, err := GetPassContext(ctx, "Pass:", tty.Slave, buf)
+	if err != nil {
+		t.Fatalf("GetPassContext failed: %v", err)
+	}
+	if string(pass) != "abc" {
+		t.Errorf("GetPassContext got: %q want: %q", pass, "abc")
+	}
+}
+
+// TestGetPassTimeout tests giving up on a password not typed in time.
+func TestGetPassTimeout(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	backup, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	out := readMaster(tty)
+	go func() {
+		if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+			tty.Master.Write([]byte("abc"))
+		}
+	}()
+	buf := make([]byte, 16)
+	start := time.Now()
+	_, err = GetPassTimeout("Pass:", tty.Slave, buf, 3*pollInterval)
+	var terr *TimeoutError
+	if !errors.As(err, &terr) || terr.After != 3*pollInterval || !terr.Timeout() {
+		t.Fatalf("GetPassTimeout got: %v want: *TimeoutError after %v", err, 3*pollInterval)
+	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("errors.Is(%v, ErrTimeout) got: false want: true", err)
+	}
+	if took := time.Since(start); took < 3*pollInterval || took > 3*time.Second {
+		t.Errorf("GetPassTimeout took: %v want: about %v", took, 3*pollInterval)
+	}
+	for _, c := range buf {
+		if c != 0 {
+			t.Errorf("GetPassTimeout should clear buffer on timeout got: %q", buf)
+			break
+		}
+	}
+	if got, _ := Attr(tty.Slave); got != backup {
+		t.Errorf("GetPassTimeout did not restore attributes got: %v want: %v", got, backup)
+	}
+	// In time.
+	out.reset()
+	go func() {
+		if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+			tty.Master.Write([]byte("abc\n"))
+		}
+	}()
+	pass, err := GetPassTimeout("Pass:", tty.Slave, buf, 5*time.Second)
+	if err != nil {
+		t.Fatalf("GetPassTimeout failed: %v", err)
+	}
+	if string(pass) != "abc" {
+		t.Errorf("GetPassTimeout got: %q want: %q", pass, "abc")
+	}
+}
+
+// TestGetPassConfirm tests reading a password twice.
+func TestGetPassConfirm(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	tsts := []struct {
+		pass, confirm string
+		want          string
+		err           error
+	}{
+		{"secret", "secret", "secret", nil},
+		{"secret", "secreT", "", ErrMismatch},
+		{"secret", "secre", "", ErrMismatch},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.pass + "\n"))
+			}
+			if res := out.waitFor("Again:"); strings.Contains(res, "Again:") {
+				tty.Master.Write([]byte(tst.confirm + "\n"))
+			}
+		}()
+		pass, err := GetPassConfirm("Pass:", "Again:", tty.Slave)
+		if string(pass) != tst.want || err != tst.err {
+			t.Errorf("GetPassConfirm %q,%q got: %q,%v want: %q,%v", tst.pass, tst.confirm, pass, err, tst.want, tst.err)
+		}
+	}
+}
+
+// TestGetPassUTF8 tests editing and masking multibyte input.
+func TestGetPassUTF8(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	tsts := []struct {
+		in   string
+		want string
+		echo string
+	}{
+		{"aé世\x7f\x7fb\n", "ab", "Pass:***\b \b\b \b*"},
+		{"世界\x15é\n", "é", "Pass:**\b \b\b \b*"},
+		{"\xff\x7fx\n", "x", "Pass:*\b \b*"},
+	}
+	buf := make([]byte, 16)
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.in))
+			}
+		}()
+		pass, err := GetPassMasked("Pass:", tty.Slave, buf, '*')
+		if err != nil {
+			t.Errorf("GetPassMasked(%q) failed: %v", tst.in, err)
+			continue
+		}
+		if string(pass) != tst.want {
+			t.Errorf("GetPassMasked(%q) got: %q want: %q", tst.in, pass, tst.want)
+		}
+		if res := out.waitFor(tst.echo); res != tst.echo {
+			t.Errorf("GetPassMasked(%q) echoed: %q want: %q", tst.in, res, tst.echo)
+		}
+		for _, c := range buf[len(pass):] {
+			if c != 0 {
+				t.Errorf("GetPassMasked(%q) left removed bytes in buffer: %q", tst.in, buf)
+				break
+			}
+		}
+		clearbuf(buf)
+	}
+	// 世 is 3 bytes, only 2 are left so it shouldn't be stored in part.
+	out.reset()
+	go func() {
+		if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+			tty.Master.Write([]byte("ab世\n"))
+		}
+	}()
+	small := make([]byte, 4)
+	if pass, err := GetPassMasked("Pass:", tty.Slave, small, '*'); err == nil {
+		t.Errorf("GetPassMasked with partial rune got: %q want: error", pass)
+	}
+	for _, c := range small {
+		if c != 0 {
+			t.Errorf("GetPassMasked with partial rune left buffer: %q", small)
+			break
+		}
+	}
+}
+
+// TestGetPassTo tests reading a password from one terminal prompting on another.
+func TestGetPassTo(t *testing.T) {
+	in, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer in.Close()
+	out, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer out.Close()
+	inOut, outOut := readMaster(in), readMaster(out)
+	go func() {
+		if res := outOut.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+			in.Master.Write([]byte("secret\n"))
+		}
+	}()
+	pass, err := GetPassTo(in.Slave, out.Slave, "Pass:", make([]byte, 16))
+	if err != nil {
+		t.Fatalf("GetPassTo(in.Slave,out.Slave,\"Pass:\",buf) failed: %v", err)
+	}
+	if string(pass) != "secret" {
+		t.Errorf("GetPassTo got: %q want: %q", pass, "secret")
+	}
+	if res := inOut.waitFor("Pass:"); res != "" {
+		t.Errorf("GetPassTo wrote to in: %q want: %q", res, "")
+	}
+	if res := outOut.waitFor("Pass:"); res != "Pass:" {
+		t.Errorf("GetPassTo wrote to out: %q want: %q", res, "Pass:")
+	}
+}
+
+// TestGetPassDelim tests ending the password on a given delimiter only.
+func TestGetPassDelim(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	tsts := []struct {
+		in    string
+		delim byte
+		want  string
+	}{
+		{"se\rcret\n", '\n', "se\rcret"},
+		{"se\ncret\r", '\r', "se\ncret"},
+		{"ab\x7f;", ';', "ab\x7f"},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.in))
+			}
+		}()
+		pass, err := GetPassDelim("Pass:", tty.Slave, make([]byte, 16), tst.delim)
+		if err != nil || string(pass) != tst.want {
+			t.Errorf("GetPassDelim(%q, %q) got: %q,%v want: %q,<nil>", tst.in, tst.delim, pass, err, tst.want)
+		}
+	}
+}
+
+// TestGetPassFlags tests reading passwords with other Lflag bits turned off.
+func TestGetPassFlags(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	want, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	out := readMaster(tty)
+	tsts := []struct {
+		in    string
+		clear uint32
+		want  string
+	}{
+		{"secret\n", 0, "secret"},
+		{"ab\x7fc\n", ICANON, "ac"},
+		{"a\x03b\n", ICANON | ISIG, "a\x03b"},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.in))
+			}
+		}()
+		pass, err := GetPassFlags("Pass:", tty.Slave, make([]byte, 16), tst.clear)
+		if err != nil || string(pass) != tst.want {
+			t.Errorf("GetPassFlags(%q, %#o) got: %q,%v want: %q,<nil>", tst.in, tst.clear, pass, err, tst.want)
+		}
+		if res := out.waitFor("Pass:"); res != "Pass:" {
+			t.Errorf("GetPassFlags(%q, %#o) echoed: %q want: %q", tst.in, tst.clear, res, "Pass:")
+		}
+		if got, err := Attr(tty.Slave); err != nil || got != want {
+			t.Errorf("GetPassFlags left attributes: %v want: %v", got, want)
+		}
+	}
+}
+
+// TestGetPassFlushed tests input typed ahead of the prompt being discarded.
+func TestGetPassFlushed(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	// Once echoed the typed ahead input is waiting to be read.
+	tty.Master.Write([]byte("typed"))
+	if res := out.waitFor("typed"); res != "typed" {
+		t.Fatalf("typed ahead input echoed: %q want: %q", res, "typed")
+	}
+	out.reset()
+	go func() {
+		if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+			tty.Master.Write([]byte("secret\n"))
+		}
+	}()
+	pass, err := GetPassFlushed("Pass:", tty.Slave, make([]byte, 16))
+	if err != nil || string(pass) != "secret" {
+		t.Errorf("GetPassFlushed got: %q,%v want: %q,<nil>", pass, err, "secret")
+	}
+}
+
+// TestReadPassword tests reading passwords into a buffer growing as needed.
+func TestReadPassword(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tios, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tios.Cc[VERASE] = '#'
+	tios.Cc[VKILL] = '@'
+	if err := tios.Set(tty.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	out := readMaster(tty)
+	// Longer than the 4096 bytes a canonical line can be.
+	long := strings.Repeat("x", 5000)
+	tsts := []struct {
+		in, want string
+	}{
+		{"secret", "secret"},
+		{strings.Repeat("x", 1000), strings.Repeat("x", 1000)},
+		{long, long},
+		{"", ""},
+		{"ab\x7fc#d", "ad"},
+		{"xy\x15z", "z"},
+		{"xy@z", "z"},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.in + "\n"))
+			}
+		}()
+		if pass, err := ReadPassword("Pass:", tty.Slave); err != nil || pass != tst.want {
+			t.Errorf("ReadPassword of %d bytes got: %d bytes,%v want: %d bytes,<nil>", len(tst.in), len(pass), err, len(tst.want))
+		}
+		if res := out.waitFor("Pass:"); res != "Pass:" {
+			t.Errorf("ReadPassword echoed: %q want: %q", res, "Pass:")
+		}
+	}
+}
+
+// TestGetPassEOF tests Ctrl-D and EOF ending the password prompt.
+func TestGetPassEOF(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	tsts := []struct {
+		name string
+		read func(buf []byte) ([]byte, error)
+		in   string
+		want string
+		err  error
+	}{
+		{"GetPass", func(buf []byte) ([]byte, error) { return GetPass("Pass:", tty.Slave, buf) }, "\x04", "", io.EOF},
+		{"GetPassMasked", func(buf []byte) ([]byte, error) { return GetPassMasked("Pass:", tty.Slave, buf, '*') }, "\x04", "", io.EOF},
+		{"GetPassMasked", func(buf []byte) ([]byte, error) { return GetPassMasked("Pass:", tty.Slave, buf, '*') }, "ab\x04c\n", "abc", nil},
+		{"GetPass", func(buf []byte) ([]byte, error) { return GetPass("Pass:", tty.Slave, buf) }, "\n", "", nil},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("Pass:"); strings.Contains(res, "Pass:") {
+				tty.Master.Write([]byte(tst.in))
+			}
+		}()
+		pass, err := tst.read(make([]byte, 16))
+		if string(pass) != tst.want || err != tst.err {
+			t.Errorf("%s(%q) got: %q,%v want: %q,%v", tst.name, tst.in, pass, err, tst.want, tst.err)
+		}
+		if err == io.EOF && pass == nil {
+			t.Errorf("%s(%q) got: nil slice want: empty slice", tst.name, tst.in)
+		}
+	}
+}
+
+// TestGetPassReadError tests a failing read ending the password prompt.
+func TestGetPassReadError(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Slave.Close()
+	// No reader on the Master, closing it would wait for the read to finish.
+	go func() {
+		tty.Master.Write([]byte("ab"))
+		time.Sleep(50 * time.Millisecond)
+		// Hanging up the terminal makes the reads fail with EIO.
+		tty.Master.Close()
+	}()
+	buf := make([]byte, 16)
+	pass, err := GetPassMasked("Pass:", tty.Slave, buf, '*')
+	if pass != nil || !errors.Is(err, syscall.EIO) {
+		t.Errorf("GetPassMasked on hang up got: %q,%v want: nil,%v", pass, err, syscall.EIO)
+	}
+	for _, c := range buf {
+		if c != 0 {
+			t.Errorf("GetPassMasked should clear buffer on error got: %q", buf)
+			break
+		}
+	}
+}
+
+// TestGetLine tests reading a line with echo.
+func TestGetLine(t *testing.T) {
+	f, err := donormfile("TestGetLine")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestGetLine\") failed: %v", err)
+	}
+	defer f.Close()
+	buf := make([]byte, 16)
+	if _, err := GetLine("Line:", f, buf); err == nil {
+		t.Errorf("GetLine(\"Line:\",%s,buf) got: <nil> want: not a tty error", f.Name())
+	}
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	// GetLine should turn echo back on.
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	tr.Raw()
+	if err := tr.Set(tty.Slave); err != nil {
+		t.Fatalf("Set(tty.Slave) failed: %v", err)
+	}
+	out := readMaster(tty)
+	go func() {
+		if res := out.waitFor("Line:"); !strings.Contains(res, "Line:") {
+			return
+		}
+		tty.Master.Write([]byte("hello\n"))
+	}()
+	line, err := GetLine("Line:", tty.Slave, buf)
+	if err != nil {
+		t.Fatalf("GetLine(\"Line:\",tty.Slave,buf) failed: %v", err)
+	}
+	if string(line) != "hello" {
+		t.Errorf("GetLine got: %q want: %q", line, "hello")
+	}
+	if res := out.waitFor("Line:hello"); !strings.HasPrefix(res, "Line:hello") {
+		t.Errorf("GetLine echoed: %q want: %q", res, "Line:hello")
+	}
+	if got, _ := Attr(tty.Slave); got != tr {
+		t.Errorf("GetLine did not restore attributes got: %v want: %v", got, tr)
+	}
+	out.reset()
+	go func() {
+		if res := out.waitFor("Line:"); !strings.Contains(res, "Line:") {
+			return
+		}
+		tty.Master.Write([]byte("this line is too long\n"))
+	}()
+	sbuf := buf[:8]
+	if _, err := GetLine("Line:", tty.Slave, sbuf); err == nil {
+		t.Errorf("GetLine should fail got: <nil> want: ran out of bufferspace")
+	}
+	for _, c := range sbuf {
+		if c != 0 {
+			t.Errorf("GetLine should clear buffer on errors got: %q want: \"\"", sbuf)
+			break
+		}
+	}
+}
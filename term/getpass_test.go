@@ -0,0 +1,122 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestSecureCompare tests comparing equal and different secrets, including ones of different length.
+func TestSecureCompare(t *testing.T) {
+	tsts := []struct {
+		a, b string
+		want bool
+	}{
+		{"secret", "secret", true},
+		{"secret", "secreT", false},
+		{"secret", "secre", false},
+		{"", "", true},
+		{"", "a", false},
+	}
+	for _, tst := range tsts {
+		if got := SecureCompare([]byte(tst.a), []byte(tst.b)); got != tst.want {
+			t.Errorf("SecureCompare(%q, %q) got: %t want: %t", tst.a, tst.b, got, tst.want)
+		}
+	}
+}
+
+// TestZero tests clearing a buffer, and that a nil one is fine.
+func TestZero(t *testing.T) {
+	b := []byte("secret")
+	Zero(b)
+	for _, c := range b {
+		if c != 0 {
+			t.Fatalf("Zero got: %q want: all zeros", b)
+		}
+	}
+	Zero(nil)
+}
+
+// interruptedTTY is a MockTTY where every other read is cut short, alternately without an error
+// and with EINTR, like reads interrupted by signals.
+type interruptedTTY struct {
+	*MockTTY
+	reads int
+}
+
+func (it *interruptedTTY) Read(b []byte) (int, error) {
+	it.reads++
+	switch it.reads % 4 {
+	case 1:
+		return 0, nil
+	case 3:
+		return 0, syscall.EINTR
+	}
+	return it.MockTTY.Read(b)
+}
+
+// TestGetPassInterrupted tests GetPassTerminal carrying on after short and EINTR reads.
+func TestGetPassInterrupted(t *testing.T) {
+	it := &interruptedTTY{MockTTY: NewMockTTY("secret\n")}
+	buf := make([]byte, 16)
+	pass, err := GetPassTerminal("Pass:", it, buf)
+	if err != nil {
+		t.Fatalf("GetPassTerminal with interrupted reads failed: %v", err)
+	}
+	if string(pass) != "secret" {
+		t.Errorf("GetPassTerminal with interrupted reads got: %q want: %q", pass, "secret")
+	}
+}
+
+// TestGetPassOverflow tests input longer than the buffer failing with the buffer cleared.
+func TestGetPassOverflow(t *testing.T) {
+	for _, in := range []string{"toolong\n", "abcd\n", "ab\xc3"} {
+		buf := make([]byte, 4)
+		pass, err := GetPassTerminal("Pass:", NewMockTTY(in), buf)
+		if err == nil {
+			t.Errorf("GetPassTerminal(%q) with 4 byte buffer got: %q want: error", in, pass)
+		}
+		for _, c := range buf {
+			if c != 0 {
+				t.Errorf("GetPassTerminal(%q) should clear buffer on overflow got: %q", in, buf)
+				break
+			}
+		}
+	}
+}
+
+// TestReadLineGrow tests readLine growing a short buffer up to max and failing past it.
+func TestReadLineGrow(t *testing.T) {
+	tsts := []struct {
+		in   string
+		want string
+		fail bool
+	}{
+		{"secret\n", "secret", false},
+		{strings.Repeat("é", 3000) + "\n", strings.Repeat("é", 3000), false},
+		{strings.Repeat("a", readPasswordMax-1) + "\n", strings.Repeat("a", readPasswordMax-1), false},
+		{strings.Repeat("a", readPasswordMax+1) + "\n", "", true},
+	}
+	for _, tst := range tsts {
+		pbuf := make([]byte, 3)
+		pass, err := readLine(context.Background(), NewMockTTY(tst.in), &strings.Builder{}, pbuf, readMode{max: readPasswordMax})
+		if tst.fail {
+			if err == nil {
+				t.Errorf("readLine of %d bytes got: <nil> want: error", len(tst.in))
+			}
+		} else if err != nil || string(pass) != tst.want {
+			t.Errorf("readLine of %d bytes got: %d bytes,%v want: %d bytes,<nil>", len(tst.in), len(pass), err, len(tst.want))
+		}
+		for _, c := range pbuf {
+			if c != 0 {
+				t.Errorf("readLine of %d bytes left the buffer it outgrew: %q", len(tst.in), pbuf)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// SpecialKey names the keys that aren't ordinary characters.
+type SpecialKey int
+
+// Special keys, KeyRune is an ordinary character.
+const (
+	KeyRune      SpecialKey = iota // KeyRune an ordinary character, see Key.Rune
+	KeyEnter                       // KeyEnter Enter or Return
+	KeyTab                         // KeyTab Tab, Shift-Tab has ModShift set
+	KeyBackspace                   // KeyBackspace Backspace
+	KeyEscape                      // KeyEscape a lone Escape
+	KeyUp                          // KeyUp arrow up
+	KeyDown                        // KeyDown arrow down
+	KeyRight                       // KeyRight arrow right
+	KeyLeft                        // KeyLeft arrow left
+	KeyHome                        // KeyHome Home
+	KeyEnd                         // KeyEnd End
+	KeyInsert                      // KeyInsert Insert
+	KeyDelete                      // KeyDelete Delete
+	KeyPageUp                      // KeyPageUp Page Up
+	KeyPageDown                    // KeyPageDown Page Down
+	KeyF1                          // KeyF1 function key F1, F2-F12 follow in order
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyUnknown // KeyUnknown an escape sequence not recognized
+)
+
+// KeyMod are the modifier keys held, the bits are the same as in the xterm modifier parameter.
+type KeyMod int
+
+// Modifier keys.
+const (
+	ModShift KeyMod = 1 << iota // ModShift Shift held
+	ModAlt                      // ModAlt Alt or Meta held, or the key was prefixed by ESC
+	ModCtrl                     // ModCtrl Ctrl held
+)
+
+// Key is a single keypress.
+type Key struct {
+	Rune    rune       // Rune the character for KeyRune, for Ctrl-A to Ctrl-Z the lower case letter
+	Special SpecialKey // Special the key if not an ordinary character
+	Mod     KeyMod     // Mod the modifiers held
+}
+
+// escTimeout is how long ReadKey waits for the rest of an escape sequence before taking ESC as a key.
+const escTimeout = pollInterval
+
+// csiKeys maps the final byte of CSI and SS3 sequences to keys.
+var csiKeys = map[byte]SpecialKey{
+	'A': KeyUp,
+	'B': KeyDown,
+	'C': KeyRight,
+	'D': KeyLeft,
+	'H': KeyHome,
+	'F': KeyEnd,
+	'Z': KeyTab,
+	'P': KeyF1,
+	'Q': KeyF2,
+	'R': KeyF3,
+	'S': KeyF4,
+}
+
+// tildeKeys maps the number of ESC[n~ sequences to keys.
+var tildeKeys = map[int]SpecialKey{
+	1:  KeyHome,
+	2:  KeyInsert,
+	3:  KeyDelete,
+	4:  KeyEnd,
+	5:  KeyPageUp,
+	6:  KeyPageDown,
+	7:  KeyHome,
+	8:  KeyEnd,
+	11: KeyF1,
+	12: KeyF2,
+	13: KeyF3,
+	14: KeyF4,
+	15: KeyF5,
+	17: KeyF6,
+	18: KeyF7,
+	19: KeyF8,
+	20: KeyF9,
+	21: KeyF10,
+	23: KeyF11,
+	24: KeyF12,
+}
+
+// ReadKey reads a single keypress from the terminal f decoding the escape sequences sent for
+// arrows, function keys and such. The terminal is in raw mode for the read and restored after.
+// An ESC not followed by more input within escTimeout is returned as KeyEscape, ESC followed by
+// a key not starting a sequence is taken as that key with ModAlt.
+// When reading keys in a loop keep the terminal in raw mode using MakeRaw, otherwise input arriving
+// between the reads is handled by the line discipline, eg. Backspace erasing what came before it.
+// Bytes read ahead after an invalid UTF-8 byte are lost, a KeyReader keeps them for the next read.
+func ReadKey(f *os.File) (Key, error) {
+	return NewKeyReader(f).ReadKey()
+}
+
+// ReadRune reads a single UTF-8 encoded character from the terminal f and returns it with the number
+// of bytes it took. The terminal is in raw mode for the read and restored after, as for ReadKey.
+// The bytes of a character split up on the way are waited for up to escTimeout, if they don't
+// come or aren't valid UTF-8 utf8.RuneError is returned for the invalid byte. The bytes read
+// ahead after it are lost, a KeyReader keeps them for the next read.
+// Unlike ReadKey escape sequences aren't decoded, ESC is returned as any other character.
+func ReadRune(f *os.File) (rune, int, error) {
+	return NewKeyReader(f).ReadRune()
+}
+
+// KeyReader reads keypresses and characters from a terminal, keeping the bytes read ahead after an
+// invalid UTF-8 byte for the next read. Use its Read instead of the file's once reading with it so
+// none are lost. KeyReader is not safe for concurrent use.
+type KeyReader struct {
+	f   *os.File
+	b   [1]byte
+	buf []byte // buf read ahead and not used yet, returned first
+}
+
+// NewKeyReader returns a KeyReader for the terminal f.
+func NewKeyReader(f *os.File) *KeyReader {
+	return &KeyReader{f: f}
+}
+
+// ReadKey reads a single keypress, see the ReadKey function.
+func (kr *KeyReader) ReadKey() (Key, error) {
+	restore, err := keyMode(kr.f)
+	if err != nil {
+		return Key{}, err
+	}
+	defer restore()
+	c, err := kr.wait()
+	if err != nil {
+		return Key{}, err
+	}
+	return kr.decode(c)
+}
+
+// ReadRune reads a single UTF-8 encoded character, see the ReadRune function.
+func (kr *KeyReader) ReadRune() (rune, int, error) {
+	restore, err := keyMode(kr.f)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer restore()
+	c, err := kr.wait()
+	if err != nil {
+		return 0, 0, err
+	}
+	return kr.rune(c)
+}
+
+// Read reads the bytes read ahead first and then from the terminal as is, without raw mode.
+func (kr *KeyReader) Read(b []byte) (int, error) {
+	if len(kr.buf) > 0 {
+		n := copy(b, kr.buf)
+		kr.buf = kr.buf[n:]
+		return n, nil
+	}
+	return kr.f.Read(b)
+}
+
+// keyMode puts the terminal f in raw mode with reads timing out after escTimeout.
+// The returned function restores the attributes and clears the read deadline.
+func keyMode(f *os.File) (func(), error) {
+	t, err := Attr(f)
+	if err != nil {
+		return nil, err
+	}
+	raw := t
+	raw.Raw()
+	raw.Cc[VMIN] = 0
+	raw.Cc[VTIME] = byte(escTimeout / (100 * time.Millisecond))
+	if err := raw.Set(f); err != nil {
+		t.Set(f)
+		return nil, err
+	}
+	return func() {
+		f.SetReadDeadline(time.Time{})
+		t.Set(f)
+	}, nil
+}
+
+// wait reads a byte waiting for as long as it takes.
+func (kr *KeyReader) wait() (byte, error) {
+	for {
+		c, err := kr.next()
+		if err != ErrTimeout {
+			return c, err
+		}
+	}
+}
+
+// next reads a byte giving up with ErrTimeout after escTimeout.
+func (kr *KeyReader) next() (byte, error) {
+	if len(kr.buf) > 0 {
+		c := kr.buf[0]
+		kr.buf = kr.buf[1:]
+		return c, nil
+	}
+	kr.f.SetReadDeadline(time.Now().Add(escTimeout))
+	_, err := kr.f.Read(kr.b[:])
+	if pollTimeout(err) {
+		return 0, ErrTimeout
+	}
+	return kr.b[0], err
+}
+
+// decode reads the rest of the keypress starting with c.
+func (kr *KeyReader) decode(c byte) (Key, error) {
+	switch {
+	case c == 0x1b:
+		c, err := kr.next()
+		switch {
+		case err == ErrTimeout:
+			return Key{Special: KeyEscape}, nil
+		case err != nil:
+			return Key{}, err
+		case c == '[':
+			return kr.csi()
+		case c == 'O':
+			return kr.ss3()
+		}
+		k, err := kr.decode(c)
+		k.Mod |= ModAlt
+		return k, err
+	case c == '\r' || c == '\n':
+		return Key{Special: KeyEnter}, nil
+	case c == '\t':
+		return Key{Special: KeyTab}, nil
+	case c == 0x7f || c == 0x08:
+		return Key{Special: KeyBackspace}, nil
+	case c == 0:
+		return Key{Rune: ' ', Mod: ModCtrl}, nil
+	case c <= 0x1a:
+		return Key{Rune: rune(c) + 'a' - 1, Mod: ModCtrl}, nil
+	case c < 0x20:
+		// Ctrl-\ Ctrl-] Ctrl-^ and Ctrl-_
+		return Key{Rune: rune(c) + 0x40, Mod: ModCtrl}, nil
+	}
+	r, _, err := kr.rune(c)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Rune: r}, nil
+}
+
+// rune reads the rest of the UTF-8 encoded character starting with c.
+// For multi byte UTF-8 the leading byte gives the length.
+func (kr *KeyReader) rune(c byte) (rune, int, error) {
+	if c < utf8.RuneSelf {
+		return rune(c), 1, nil
+	}
+	p := []byte{c}
+	for !utf8.FullRune(p) && len(p) < utf8.UTFMax {
+		c, err := kr.next()
+		if err == ErrTimeout {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		p = append(p, c)
+	}
+	r, n := utf8.DecodeRune(p)
+	// The bytes after an invalid one can start the next character.
+	kr.buf = append(p[n:len(p):len(p)], kr.buf...)
+	return r, n, nil
+}
+
+// csi reads the rest of an ESC[ sequence.
+func (kr *KeyReader) csi() (Key, error) {
+	var params []byte
+	for len(params) < 16 {
+		c, err := kr.next()
+		if err == ErrTimeout {
+			break
+		}
+		if err != nil {
+			return Key{}, err
+		}
+		if c >= 0x40 && c <= 0x7e {
+			return csiKey(params, c), nil
+		}
+		params = append(params, c)
+	}
+	return Key{Special: KeyUnknown}, nil
+}
+
+// ss3 reads the rest of an ESC O sequence, sent for F1-F4 and by some terminals for the arrows.
+func (kr *KeyReader) ss3() (Key, error) {
+	c, err := kr.next()
+	if err == ErrTimeout {
+		return Key{Special: KeyUnknown}, nil
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	return csiKey(nil, c), nil
+}
+
+// csiKey maps the parameters and final byte of a sequence to a Key.
+// The modifiers come as the second parameter, eg. ESC[1;5A for Ctrl-Up.
+func csiKey(params []byte, final byte) Key {
+	k := Key{Special: KeyUnknown}
+	fields := bytes.Split(params, []byte(";"))
+	if final == '~' {
+		n, _ := strconv.Atoi(string(fields[0]))
+		if sk, ok := tildeKeys[n]; ok {
+			k.Special = sk
+		}
+	} else if sk, ok := csiKeys[final]; ok {
+		k.Special = sk
+	}
+	if final == 'Z' {
+		k.Mod = ModShift
+	}
+	if len(fields) > 1 {
+		if mod, err := strconv.Atoi(string(fields[1])); err == nil && mod > 1 {
+			k.Mod |= KeyMod(mod-1) & (ModShift | ModAlt | ModCtrl)
+		}
+	}
+	return k
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// TestReadKey tests decoding keypresses sent to a terminal.
+func TestReadKey(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	// Same as a program reading keys, input arriving between the reads is left alone.
+	restore, err := MakeRaw(p.Slave)
+	if err != nil {
+		t.Fatalf("MakeRaw failed: %v", err)
+	}
+	defer restore()
+	want, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tsts := []struct {
+		in   string
+		keys []Key
+	}{
+		{"a", []Key{{Rune: 'a'}}},
+		{"é世", []Key{{Rune: 'é'}, {Rune: '世'}}},
+		{"\r\t\x7f", []Key{{Special: KeyEnter}, {Special: KeyTab}, {Special: KeyBackspace}}},
+		{"\x01\x1a\x00", []Key{{Rune: 'a', Mod: ModCtrl}, {Rune: 'z', Mod: ModCtrl}, {Rune: ' ', Mod: ModCtrl}}},
+		{"\x1b[A\x1b[B\x1b[C\x1b[D", []Key{{Special: KeyUp}, {Special: KeyDown}, {Special: KeyRight}, {Special: KeyLeft}}},
+		{"\x1bOA\x1bOP\x1bOS", []Key{{Special: KeyUp}, {Special: KeyF1}, {Special: KeyF4}}},
+		{"\x1b[H\x1b[F\x1b[1~\x1b[4~", []Key{{Special: KeyHome}, {Special: KeyEnd}, {Special: KeyHome}, {Special: KeyEnd}}},
+		{"\x1b[2~\x1b[3~\x1b[5~\x1b[6~", []Key{{Special: KeyInsert}, {Special: KeyDelete}, {Special: KeyPageUp}, {Special: KeyPageDown}}},
+		{"\x1b[15~\x1b[24~", []Key{{Special: KeyF5}, {Special: KeyF12}}},
+		{"\x1b[1;5A\x1b[1;2D\x1b[3;3~\x1b[Z", []Key{{Special: KeyUp, Mod: ModCtrl}, {Special: KeyLeft, Mod: ModShift}, {Special: KeyDelete, Mod: ModAlt}, {Special: KeyTab, Mod: ModShift}}},
+		{"\x1bx\x1b\x1b[A", []Key{{Rune: 'x', Mod: ModAlt}, {Special: KeyUp, Mod: ModAlt}}},
+		{"\x1b[99~\x1b[9q", []Key{{Special: KeyUnknown}, {Special: KeyUnknown}}},
+		{"\x1b", []Key{{Special: KeyEscape}}},
+	}
+	for _, tst := range tsts {
+		if _, err := p.Master.Write([]byte(tst.in)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		for _, k := range tst.keys {
+			got, err := ReadKey(p.Slave)
+			if err != nil {
+				t.Fatalf("ReadKey for %q failed: %v", tst.in, err)
+			}
+			if got != k {
+				t.Errorf("ReadKey for %q got: %+v want: %+v", tst.in, got, k)
+			}
+		}
+	}
+	// A key sent after a pause shouldn't be taken as part of the ESC before it.
+	p.Master.Write([]byte("\x1b"))
+	go func() {
+		time.Sleep(3 * escTimeout)
+		p.Master.Write([]byte("[A"))
+	}()
+	for _, k := range []Key{{Special: KeyEscape}, {Rune: '['}, {Rune: 'A'}} {
+		if got, err := ReadKey(p.Slave); err != nil || got != k {
+			t.Errorf("ReadKey after pause got: %+v, %v want: %+v, <nil>", got, err, k)
+		}
+	}
+	if got, err := Attr(p.Slave); err != nil || got != want {
+		t.Errorf("ReadKey left attributes: %v want: %v", got, want)
+	}
+	f, err := donormfile("TestReadKey")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestReadKey\") failed: %v", err)
+	}
+	defer f.Close()
+	if _, err := ReadKey(f); err == nil {
+		t.Errorf("ReadKey for normal file got: <nil> want: not a tty error")
+	}
+}
+
+// TestReadRune tests decoding the UTF-8 characters sent to a terminal.
+func TestReadRune(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	restore, err := MakeRaw(p.Slave)
+	if err != nil {
+		t.Fatalf("MakeRaw failed: %v", err)
+	}
+	defer restore()
+	type runeSize struct {
+		r rune
+		n int
+	}
+	tsts := []struct {
+		in    string
+		runes []runeSize
+	}{
+		{"a\x1b", []runeSize{{'a', 1}, {0x1b, 1}}},
+		{"é世😀", []runeSize{{'é', 2}, {'世', 3}, {'😀', 4}}},
+		{"\xffb", []runeSize{{utf8.RuneError, 1}, {'b', 1}}},
+		{"\xc3a", []runeSize{{utf8.RuneError, 1}, {'a', 1}}},
+		{"\xe4\xb8é", []runeSize{{utf8.RuneError, 1}, {utf8.RuneError, 1}, {'é', 2}}},
+		{"\xe4", []runeSize{{utf8.RuneError, 1}}},
+	}
+	kr := NewKeyReader(p.Slave)
+	for _, tst := range tsts {
+		if _, err := p.Master.Write([]byte(tst.in)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		for _, want := range tst.runes {
+			r, n, err := kr.ReadRune()
+			if err != nil || r != want.r || n != want.n {
+				t.Errorf("ReadRune for %q got: %q,%d,%v want: %q,%d,<nil>", tst.in, r, n, err, want.r, want.n)
+			}
+		}
+	}
+	// The byte read ahead after an invalid one is returned by Read.
+	p.Master.Write([]byte("\xc3a"))
+	if r, _, err := kr.ReadRune(); err != nil || r != utf8.RuneError {
+		t.Errorf("ReadRune for %q got: %q,%v want: %q,<nil>", "\xc3a", r, err, utf8.RuneError)
+	}
+	b := make([]byte, 4)
+	if n, err := kr.Read(b); err != nil || string(b[:n]) != "a" {
+		t.Errorf("Read after ReadRune got: %q,%v want: %q,<nil>", b[:n], err, "a")
+	}
+	// The rest of a character coming after a short pause.
+	p.Master.Write([]byte("\xe4\xb8"))
+	go func() {
+		time.Sleep(escTimeout / 4)
+		p.Master.Write([]byte("\x96"))
+	}()
+	if r, n, err := ReadRune(p.Slave); err != nil || r != '世' || n != 3 {
+		t.Errorf("ReadRune split got: %q,%d,%v want: %q,3,<nil>", r, n, err, '世')
+	}
+	f, err := donormfile("TestReadRune")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestReadRune\") failed: %v", err)
+	}
+	defer f.Close()
+	if _, _, err := ReadRune(f); err == nil {
+		t.Errorf("ReadRune for normal file got: <nil> want: not a tty error")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "strings"
+
+// ModemBits are the modem control lines of a serial port.
+type ModemBits int
+
+// Modem control lines, the bits are the TIOCM_ ones and the same on all the supported platforms.
+const (
+	ModemDTR ModemBits = 0x002 // ModemDTR Data Terminal Ready, output
+	ModemRTS ModemBits = 0x004 // ModemRTS Request To Send, output
+	ModemCTS ModemBits = 0x020 // ModemCTS Clear To Send, input
+	ModemDCD ModemBits = 0x040 // ModemDCD Data Carrier Detect, input
+	ModemRI  ModemBits = 0x080 // ModemRI Ring Indicator, input
+	ModemDSR ModemBits = 0x100 // ModemDSR Data Set Ready, input
+)
+
+// modemNames are the names of the lines in the order String lists them.
+var modemNames = []struct {
+	name string
+	bit  ModemBits
+}{
+	{"dtr", ModemDTR}, {"rts", ModemRTS}, {"cts", ModemCTS}, {"dcd", ModemDCD}, {"ri", ModemRI}, {"dsr", ModemDSR},
+}
+
+// String lists the lines that are set, eg. "dtr|rts|cts".
+func (m ModemBits) String() string {
+	var set []string
+	for _, n := range modemNames {
+		if m&n.bit != 0 {
+			set = append(set, n.name)
+		}
+	}
+	return strings.Join(set, "|")
+}
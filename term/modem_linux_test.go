@@ -0,0 +1,32 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestModemBits tests the modem line IOCTLs are done, a PTY has no modem lines so they fail.
+func TestModemBits(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	var ierr *IoctlError
+	if _, err := ModemStatus(tty.Slave); !errors.As(err, &ierr) || ierr.Op != "TIOCMGET" {
+		t.Errorf("ModemStatus of PTY got: %v want: TIOCMGET IoctlError", err)
+	}
+	if err := SetModemBits(tty.Slave, ModemDTR); !errors.As(err, &ierr) || ierr.Op != "TIOCMBIS" {
+		t.Errorf("SetModemBits of PTY got: %v want: TIOCMBIS IoctlError", err)
+	}
+	if err := ClearModemBits(tty.Slave, ModemDTR|ModemRTS); !errors.As(err, &ierr) || ierr.Op != "TIOCMBIC" {
+		t.Errorf("ClearModemBits of PTY got: %v want: TIOCMBIC IoctlError", err)
+	}
+	if got, want := (ModemDTR | ModemCTS | ModemDSR).String(), "dtr|cts|dsr"; got != want {
+		t.Errorf("ModemBits.String() got: %q want: %q", got, want)
+	}
+}
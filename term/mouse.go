@@ -0,0 +1,137 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+)
+
+// MouseMode selects what mouse events the terminal reports.
+type MouseMode int
+
+// Mouse reporting modes for SetMouseMode.
+const (
+	MouseOff    MouseMode = iota // MouseOff no mouse reporting
+	MouseClick                   // MouseClick button presses and releases, ?1000
+	MouseDrag                    // MouseDrag also motion while a button is held, ?1002
+	MouseMotion                  // MouseMotion also motion with no button held, ?1003
+)
+
+// mouseModes are the xterm private modes turned on for each MouseMode.
+var mouseModes = [...]string{
+	MouseClick:  "1000",
+	MouseDrag:   "1002",
+	MouseMotion: "1003",
+}
+
+// SetMouseMode sets the mouse reporting of terminal f to mode.
+// The events are reported in the SGR format (?1006) decoded by ParseMouseEvent.
+// Any other mouse mode is turned off first so switching between modes works.
+func SetMouseMode(f *os.File, mode MouseMode) error {
+	if mode < MouseOff || mode > MouseMotion {
+		return errors.New("mode: " + strconv.Itoa(int(mode)) + " not a valid mouse mode")
+	}
+	seq := CSI + "?1006l" + CSI + "?1003l" + CSI + "?1002l" + CSI + "?1000l"
+	if mode != MouseOff {
+		seq += CSI + "?" + mouseModes[mode] + "h" + CSI + "?1006h"
+	}
+	_, err := f.Write([]byte(seq))
+	return err
+}
+
+// MouseButton is the button of a MouseEvent.
+type MouseButton int
+
+// Mouse buttons.
+const (
+	MouseLeft       MouseButton = iota // MouseLeft left button
+	MouseMiddle                        // MouseMiddle middle button
+	MouseRight                         // MouseRight right button
+	MouseNone                          // MouseNone no button, motion with no button held
+	MouseWheelUp                       // MouseWheelUp wheel scrolled up
+	MouseWheelDown                     // MouseWheelDown wheel scrolled down
+	MouseWheelLeft                     // MouseWheelLeft wheel scrolled left
+	MouseWheelRight                    // MouseWheelRight wheel scrolled right
+	MouseButton8                       // MouseButton8 extra button 8, usually Back
+	MouseButton9                       // MouseButton9 extra button 9, usually Forward
+	MouseButton10                      // MouseButton10 extra button 10
+	MouseButton11                      // MouseButton11 extra button 11
+)
+
+// MouseEvent is a decoded SGR mouse report.
+type MouseEvent struct {
+	Button MouseButton // Button the event is for
+	X      int         // X column counting from 1
+	Y      int         // Y row counting from 1
+	Press  bool        // Press true for a press, false for a release
+	Motion bool        // Motion the mouse moved
+	Shift  bool        // Shift key held
+	Alt    bool        // Alt key held
+	Ctrl   bool        // Ctrl key held
+}
+
+// Errors returned by ParseMouseEvent.
+var (
+	ErrNotMouseEvent   = errors.New("not a SGR mouse event")      // ErrNotMouseEvent seq doesn't start with a mouse report
+	ErrShortMouseEvent = errors.New("incomplete SGR mouse event") // ErrShortMouseEvent more bytes are needed to decode the report
+)
+
+// ParseMouseEvent decodes the SGR mouse report ESC[<b;x;yM (press) or ESC[<b;x;ym (release)
+// at the start of seq. The number of bytes the report used is returned along with the event.
+// If seq holds the start of a report but not all of it ErrShortMouseEvent is returned.
+// The button codes 64-67 are the wheel, 128-131 the extra buttons 8-11, with both bits set the
+// report isn't valid and ErrNotMouseEvent is returned.
+func ParseMouseEvent(seq []byte) (MouseEvent, int, error) {
+	var ev MouseEvent
+	prefix := []byte(CSI + "<")
+	if !bytes.HasPrefix(seq, prefix) {
+		if bytes.HasPrefix(prefix, seq) {
+			return ev, 0, ErrShortMouseEvent
+		}
+		return ev, 0, ErrNotMouseEvent
+	}
+	end := bytes.IndexAny(seq, "Mm")
+	if end < 0 {
+		for _, c := range seq[len(prefix):] {
+			if c != ';' && (c < '0' || c > '9') {
+				return ev, 0, ErrNotMouseEvent
+			}
+		}
+		return ev, 0, ErrShortMouseEvent
+	}
+	fields := bytes.Split(seq[len(prefix):end], []byte(";"))
+	if len(fields) != 3 {
+		return ev, 0, ErrNotMouseEvent
+	}
+	var nums [3]int
+	for i, fld := range fields {
+		n, err := strconv.Atoi(string(fld))
+		if err != nil || n < 0 {
+			return ev, 0, ErrNotMouseEvent
+		}
+		nums[i] = n
+	}
+	b := nums[0]
+	switch {
+	case b&192 == 192:
+		return ev, 0, ErrNotMouseEvent
+	case b&128 != 0:
+		ev.Button = MouseButton8 + MouseButton(b&3)
+	case b&64 != 0:
+		ev.Button = MouseWheelUp + MouseButton(b&3)
+	default:
+		ev.Button = MouseButton(b & 3)
+	}
+	ev.Shift = b&4 != 0
+	ev.Alt = b&8 != 0
+	ev.Ctrl = b&16 != 0
+	ev.Motion = b&32 != 0
+	ev.X, ev.Y = nums[1], nums[2]
+	ev.Press = seq[end] == 'M'
+	return ev, end + 1, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"os"
+	"testing"
+)
+
+// TestSetMouseMode tests the sequences written for each of the mouse modes.
+func TestSetMouseMode(t *testing.T) {
+	off := "\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l"
+	tsts := []struct {
+		mode MouseMode
+		want string
+	}{
+		{MouseOff, off},
+		{MouseClick, off + "\x1b[?1000h\x1b[?1006h"},
+		{MouseDrag, off + "\x1b[?1002h\x1b[?1006h"},
+		{MouseMotion, off + "\x1b[?1003h\x1b[?1006h"},
+	}
+	for _, tst := range tsts {
+		got := screenOutput(t, func(w *os.File) {
+			if err := SetMouseMode(w, tst.mode); err != nil {
+				t.Errorf("SetMouseMode(%d) failed: %v", tst.mode, err)
+			}
+		})
+		if got != tst.want {
+			t.Errorf("SetMouseMode(%d) wrote: %q want: %q", tst.mode, got, tst.want)
+		}
+	}
+	screenOutput(t, func(w *os.File) {
+		if err := SetMouseMode(w, MouseMotion+1); err == nil {
+			t.Errorf("SetMouseMode(%d) got: <nil> want: invalid mode error", MouseMotion+1)
+		}
+	})
+}
+
+// TestParseMouseEvent tests decoding SGR mouse reports and rejecting what isn't one.
+func TestParseMouseEvent(t *testing.T) {
+	tsts := []struct {
+		seq  string
+		want MouseEvent
+		n    int
+		err  error
+	}{
+		{"\x1b[<0;10;20M", MouseEvent{Button: MouseLeft, X: 10, Y: 20, Press: true}, 11, nil},
+		{"\x1b[<2;1;1mrest", MouseEvent{Button: MouseRight, X: 1, Y: 1}, 9, nil},
+		{"\x1b[<1;5;6M", MouseEvent{Button: MouseMiddle, X: 5, Y: 6, Press: true}, 9, nil},
+		{"\x1b[<35;7;8M", MouseEvent{Button: MouseNone, X: 7, Y: 8, Press: true, Motion: true}, 10, nil},
+		{"\x1b[<64;3;4M", MouseEvent{Button: MouseWheelUp, X: 3, Y: 4, Press: true}, 10, nil},
+		{"\x1b[<65;3;4M", MouseEvent{Button: MouseWheelDown, X: 3, Y: 4, Press: true}, 10, nil},
+		{"\x1b[<66;3;4M", MouseEvent{Button: MouseWheelLeft, X: 3, Y: 4, Press: true}, 10, nil},
+		{"\x1b[<67;3;4M", MouseEvent{Button: MouseWheelRight, X: 3, Y: 4, Press: true}, 10, nil},
+		{"\x1b[<128;3;4M", MouseEvent{Button: MouseButton8, X: 3, Y: 4, Press: true}, 11, nil},
+		{"\x1b[<129;3;4m", MouseEvent{Button: MouseButton9, X: 3, Y: 4}, 11, nil},
+		{"\x1b[<131;3;4M", MouseEvent{Button: MouseButton11, X: 3, Y: 4, Press: true}, 11, nil},
+		{"\x1b[<162;3;4M", MouseEvent{Button: MouseButton10, X: 3, Y: 4, Press: true, Motion: true}, 11, nil},
+		{"\x1b[<192;3;4M", MouseEvent{}, 0, ErrNotMouseEvent},
+		{"\x1b[<28;300;200M", MouseEvent{Button: MouseLeft, X: 300, Y: 200, Press: true, Shift: true, Alt: true, Ctrl: true}, 14, nil},
+		{"\x1b[<0;10", MouseEvent{}, 0, ErrShortMouseEvent},
+		{"\x1b[", MouseEvent{}, 0, ErrShortMouseEvent},
+		{"\x1b[A", MouseEvent{}, 0, ErrNotMouseEvent},
+		{"\x1b[<0;10xM", MouseEvent{}, 0, ErrNotMouseEvent},
+		{"\x1b[<0;10M", MouseEvent{}, 0, ErrNotMouseEvent},
+		{"abc", MouseEvent{}, 0, ErrNotMouseEvent},
+	}
+	for _, tst := range tsts {
+		ev, n, err := ParseMouseEvent([]byte(tst.seq))
+		if ev != tst.want || n != tst.n || err != tst.err {
+			t.Errorf("ParseMouseEvent(%q) got: %+v,%d,%v want: %+v,%d,%v", tst.seq, ev, n, err, tst.want, tst.n, tst.err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"os"
+	"strconv"
+)
+
+// OutputKind is what a file is connected to, see OutputMode.
+type OutputKind int
+
+// Kinds of files returned by OutputMode.
+const (
+	OutputUnknown    OutputKind = iota // OutputUnknown the file couldn't be checked, eg. it's closed
+	OutputTerminal                     // OutputTerminal a tty
+	OutputPipe                         // OutputPipe a pipe or FIFO, eg. piped into another program
+	OutputFile                         // OutputFile a regular file, eg. redirected with >
+	OutputCharDevice                   // OutputCharDevice a character device that isn't a tty, eg. /dev/null
+	OutputOther                        // OutputOther anything else, eg. a socket
+)
+
+// String implements the Stringer interface.
+func (k OutputKind) String() string {
+	switch k {
+	case OutputUnknown:
+		return "unknown"
+	case OutputTerminal:
+		return "terminal"
+	case OutputPipe:
+		return "pipe"
+	case OutputFile:
+		return "file"
+	case OutputCharDevice:
+		return "chardevice"
+	case OutputOther:
+		return "other"
+	}
+	return "OutputKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// OutputMode tells what the file f is connected to.
+// Programs use it to only turn on colors, progress bars and prompts when writing to a terminal.
+func OutputMode(f *os.File) OutputKind {
+	if Isatty(f) {
+		return OutputTerminal
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return OutputUnknown
+	}
+	switch mode := fi.Mode(); {
+	case mode.IsRegular():
+		return OutputFile
+	case mode&os.ModeNamedPipe != 0:
+		return OutputPipe
+	case mode&os.ModeCharDevice != 0:
+		return OutputCharDevice
+	}
+	return OutputOther
+}
@@ -0,0 +1,56 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"os"
+	"testing"
+)
+
+// TestOutputMode tests telling the kinds of files apart.
+func TestOutputMode(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	f, err := donormfile("TestOutputMode")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestOutputMode\") failed: %v", err)
+	}
+	defer f.Close()
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q) failed: %v", os.DevNull, err)
+	}
+	defer null.Close()
+	closed, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Open(%q) failed: %v", os.DevNull, err)
+	}
+	closed.Close()
+	tsts := []struct {
+		name string
+		f    *os.File
+		want OutputKind
+	}{
+		{"slave", p.Slave, OutputTerminal},
+		{"pipe", w, OutputPipe},
+		{"file", f, OutputFile},
+		{"null", null, OutputCharDevice},
+		{"closed", closed, OutputUnknown},
+	}
+	for _, tst := range tsts {
+		if got := OutputMode(tst.f); got != tst.want {
+			t.Errorf("OutputMode(%s) got: %v want: %v", tst.name, got, tst.want)
+		}
+	}
+}
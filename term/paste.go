@@ -0,0 +1,38 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"os"
+)
+
+// Bracketed paste mode sequences.
+const (
+	PasteOn    = CSI + "?2004h" // PasteOn turns bracketed paste mode on
+	PasteOff   = CSI + "?2004l" // PasteOff turns bracketed paste mode off
+	PasteStart = CSI + "200~"   // PasteStart sent by the terminal before pasted text
+	PasteEnd   = CSI + "201~"   // PasteEnd sent by the terminal after pasted text
+)
+
+// SetBracketedPaste turns bracketed paste mode on or off for the terminal f.
+// With it on the terminal wraps pasted text in PasteStart and PasteEnd so it can be told apart from typed input.
+func SetBracketedPaste(f *os.File, on bool) error {
+	seq := PasteOff
+	if on {
+		seq = PasteOn
+	}
+	_, err := f.Write([]byte(seq))
+	return err
+}
+
+// StripPasteMarkers removes the bracketed paste markers from data.
+// pasted is true if data held the start of a paste.
+func StripPasteMarkers(data []byte) (text []byte, pasted bool) {
+	pasted = bytes.Contains(data, []byte(PasteStart))
+	text = bytes.ReplaceAll(data, []byte(PasteStart), nil)
+	text = bytes.ReplaceAll(text, []byte(PasteEnd), nil)
+	return text, pasted
+}
@@ -0,0 +1,55 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+// TestSetBracketedPaste tests the sequences written to turn bracketed paste on and off.
+func TestSetBracketedPaste(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	if err := SetBracketedPaste(w, true); err != nil {
+		t.Fatalf("SetBracketedPaste(w, true) failed: %v", err)
+	}
+	if err := SetBracketedPaste(w, false); err != nil {
+		t.Fatalf("SetBracketedPaste(w, false) failed: %v", err)
+	}
+	w.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if want := "\x1b[?2004h\x1b[?2004l"; string(got) != want {
+		t.Errorf("SetBracketedPaste wrote: %q want: %q", got, want)
+	}
+}
+
+// TestStripPasteMarkers tests removing the paste markers and reporting whether text was pasted.
+func TestStripPasteMarkers(t *testing.T) {
+	tsts := []struct {
+		in, text string
+		pasted   bool
+	}{
+		{"typed", "typed", false},
+		{"\x1b[200~pasted\ntext\x1b[201~", "pasted\ntext", true},
+		{"a\x1b[200~b\x1b[201~c", "abc", true},
+		{"\x1b[200~split", "split", true},
+		{"rest\x1b[201~", "rest", false},
+		{"", "", false},
+	}
+	for _, tst := range tsts {
+		text, pasted := StripPasteMarkers([]byte(tst.in))
+		if string(text) != tst.text || pasted != tst.pasted {
+			t.Errorf("StripPasteMarkers(%q) got: %q,%t want: %q,%t", tst.in, text, pasted, tst.text, tst.pasted)
+		}
+	}
+}
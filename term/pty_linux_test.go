@@ -0,0 +1,520 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestStart tests starting a command on the PTY.
+func TestStart(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	out := readMaster(tty)
+	// tty(1) fails if stdin is not a terminal, stty(1) the same for the controlling terminal.
+	cmd := exec.Command("/bin/sh", "-c", "tty; stty -F /dev/tty size")
+	if err := tty.Start(cmd); err != nil {
+		t.Fatalf("Start(%v) failed: %v", cmd.Args, err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("%v failed: %v output: %q", cmd.Args, err, out.waitFor(name))
+	}
+	if res := out.waitFor(name); !strings.Contains(res, name) {
+		t.Errorf("Start got output: %q want: %q", res, name)
+	}
+	if err := (&PTY{}).Start(exec.Command("/bin/true")); err != ErrNilSlave {
+		t.Errorf("Start with nil Slave got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestRun tests running a program on the PTY and waiting for it.
+func TestRun(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := tty.Wait(); err != ErrNoProcess {
+		t.Errorf("Wait before Run got: %v want: %v", err, ErrNoProcess)
+	}
+	out := readMaster(tty)
+	proc, err := tty.Run("/bin/sh", "-c", "echo ran in $(tty)")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if proc.Pid <= 0 {
+		t.Errorf("Run got pid: %d want: > 0", proc.Pid)
+	}
+	if err := tty.Wait(); err != nil {
+		t.Errorf("Wait failed: %v", err)
+	}
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	if res := out.waitFor("ran in " + name); !strings.Contains(res, "ran in "+name) {
+		t.Errorf("Run got output: %q want: %q", res, "ran in "+name)
+	}
+	if _, err := tty.Run("/bin/sh", "-c", "exit 3"); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var exitErr *exec.ExitError
+	if err := tty.Wait(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Wait got: %v want: exit status 3", err)
+	}
+	if _, err := tty.Run("/nonexistent/program"); err == nil {
+		t.Errorf("Run of missing program got: <nil> want: error")
+	}
+}
+
+// TestPTYReadWrite tests using the PTY as an io.ReadWriteCloser.
+func TestPTYReadWrite(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	var rwc io.ReadWriteCloser = tty
+	defer rwc.Close()
+	// Raw mode so nothing is echoed or translated.
+	tios, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tios.Raw()
+	if err := tios.Set(tty.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := io.WriteString(rwc, "input"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	b := make([]byte, len("input"))
+	if _, err := io.ReadFull(tty.Slave, b); err != nil {
+		t.Fatalf("Slave.Read failed: %v", err)
+	}
+	if got, want := string(b), "input"; got != want {
+		t.Errorf("Write got: %q want: %q", got, want)
+	}
+	if _, err := tty.Slave.Write([]byte("output")); err != nil {
+		t.Fatalf("Slave.Write failed: %v", err)
+	}
+	b = make([]byte, len("output"))
+	if _, err := io.ReadFull(rwc, b); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got, want := string(b), "output"; got != want {
+		t.Errorf("Read got: %q want: %q", got, want)
+	}
+}
+
+// TestStartAndCloseSlave tests reading the Master ending once the command started is done.
+func TestStartAndCloseSlave(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	cmd := exec.Command("/bin/echo", "done")
+	if err := tty.StartAndCloseSlave(cmd); err != nil {
+		t.Fatalf("StartAndCloseSlave(%v) failed: %v", cmd.Args, err)
+	}
+	if tty.Slave != nil {
+		t.Errorf("StartAndCloseSlave Slave got: %v want: <nil>", tty.Slave)
+	}
+	// ReadAll only returns once reading the Master fails, EOF is given as nil.
+	out, err := io.ReadAll(tty.Master)
+	if err != nil && !IsPTYClosed(err) {
+		t.Errorf("Read of Master after exit got: %v want: EIO or EOF", err)
+	}
+	if !strings.Contains(string(out), "done") {
+		t.Errorf("StartAndCloseSlave got output: %q want: %q", out, "done")
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("%v failed: %v", cmd.Args, err)
+	}
+	if err := (&PTY{}).StartAndCloseSlave(exec.Command("/bin/true")); err != ErrNilSlave {
+		t.Errorf("StartAndCloseSlave with nil Slave got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestOpenPTYMaster tests opening the slave of a master only PTY by name.
+func TestOpenPTYMaster(t *testing.T) {
+	tty, err := OpenPTYMaster()
+	if err != nil {
+		t.Fatalf("OpenPTYMaster failed: %v", err)
+	}
+	if tty.Slave != nil {
+		t.Errorf("OpenPTYMaster Slave got: %v want: <nil>", tty.Slave)
+	}
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	slave, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q) failed: %v", name, err)
+	}
+	defer slave.Close()
+	if !Isatty(slave) {
+		t.Errorf("Isatty(%q) got: false want: true", name)
+	}
+	if _, err := tty.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	line, err := GetLine("", slave, make([]byte, 64))
+	if err != nil {
+		t.Fatalf("GetLine failed: %v", err)
+	}
+	if got, want := string(line), "hello"; got != want {
+		t.Errorf("GetLine got: %q want: %q", got, want)
+	}
+	if err := tty.Close(); err != nil {
+		t.Errorf("Close got: %v want: <nil>", err)
+	}
+}
+
+// TestResize tests resizing a PTY signalling the command running on it.
+func TestResize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := tty.Resize(24, 80); err != nil {
+		t.Fatalf("Resize(24, 80) with nothing running failed: %v", err)
+	}
+	out := readMaster(tty)
+	cmd := exec.Command("/bin/sh", "-c", "trap 'echo WINCH' WINCH; echo ready; while :; do sleep 0.05; done")
+	if err := tty.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	if got := out.waitFor("ready"); !strings.Contains(got, "ready") {
+		t.Fatalf("Start output got: %q want: ready", got)
+	}
+	// Same size as before, the kernel won't signal so the SIGWINCH has to come from Resize.
+	if err := tty.Resize(24, 80); err != nil {
+		t.Fatalf("Resize(24, 80) failed: %v", err)
+	}
+	if got := out.waitFor("WINCH"); !strings.Contains(got, "WINCH") {
+		t.Errorf("Resize output got: %q want: WINCH", got)
+	}
+	rows, cols, err := GetSize(tty.Slave)
+	if err != nil {
+		t.Fatalf("GetSize failed: %v", err)
+	}
+	if rows != 24 || cols != 80 {
+		t.Errorf("GetSize got rows: %d cols: %d want rows: 24 cols: 80", rows, cols)
+	}
+	if err := tty.Resize(-1, 80); err == nil {
+		t.Errorf("Resize(-1, 80) got: <nil> want: invalid size error")
+	}
+	if err := (&PTY{}).Resize(24, 80); err != ErrNilSlave {
+		t.Errorf("Resize with nil PTY files got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestOpenPTYRaw tests bytes passing a raw PTY untouched.
+func TestOpenPTYRaw(t *testing.T) {
+	tty, err := OpenPTYRaw()
+	if err != nil {
+		t.Fatalf("OpenPTYRaw failed: %v", err)
+	}
+	defer tty.Close()
+	tios, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if tios.Lflag&(ICANON|ECHO|ISIG) != 0 || tios.Oflag&OPOST != 0 {
+		t.Errorf("OpenPTYRaw Lflag: %#o Oflag: %#o want ICANON, ECHO, ISIG and OPOST cleared", tios.Lflag, tios.Oflag)
+	}
+	// Ctrl-C, CR and LF should all come through as is.
+	in := "\x03a\rb\n"
+	if _, err := tty.Write([]byte(in)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	b := make([]byte, len(in))
+	if _, err := io.ReadFull(tty.Slave, b); err != nil {
+		t.Fatalf("Slave.Read failed: %v", err)
+	}
+	if got := string(b); got != in {
+		t.Errorf("Slave.Read got: %q want: %q", got, in)
+	}
+	if _, err := tty.Slave.Write([]byte("c\n")); err != nil {
+		t.Fatalf("Slave.Write failed: %v", err)
+	}
+	b = make([]byte, 2)
+	if _, err := io.ReadFull(tty, b); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got, want := string(b), "c\n"; got != want {
+		t.Errorf("Read got: %q want: %q", got, want)
+	}
+	if err := (&PTY{}).SetRaw(); err != ErrNilSlave {
+		t.Errorf("SetRaw with nil Slave got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestTcgetpgrp tests getting the foreground process group of a command running on the PTY.
+func TestTcgetpgrp(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	out := readMaster(tty)
+	cmd := exec.Command("/bin/sh", "-c", "echo ready; while :; do sleep 0.05; done")
+	if err := tty.Start(cmd); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	if got := out.waitFor("ready"); !strings.Contains(got, "ready") {
+		t.Fatalf("Start output got: %q want: ready", got)
+	}
+	// Start puts the command in a new session, its pid is the process group.
+	if pgrp, err := Tcgetpgrp(tty.Master); err != nil || pgrp != cmd.Process.Pid {
+		t.Errorf("Tcgetpgrp got: %d,%v want: %d,<nil>", pgrp, err, cmd.Process.Pid)
+	}
+	// Not our controlling terminal.
+	if err := Tcsetpgrp(tty.Slave, syscall.Getpgrp()); !errors.Is(err, syscall.ENOTTY) {
+		t.Errorf("Tcsetpgrp of other terminal got: %v want: %v", err, syscall.ENOTTY)
+	}
+}
+
+// TestOpenPTYOwned tests the owner and permissions of the Slave being set.
+func TestOpenPTYOwned(t *testing.T) {
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		uid, gid = 4242, 4343
+	}
+	tty, err := OpenPTYOwned(uid, gid, 0600)
+	if err != nil {
+		t.Fatalf("OpenPTYOwned(%d, %d, 0600) failed: %v", uid, gid, err)
+	}
+	defer tty.Close()
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("Stat(%q) failed: %v", name, err)
+	}
+	st := fi.Sys().(*syscall.Stat_t)
+	if int(st.Uid) != uid || int(st.Gid) != gid || fi.Mode().Perm() != 0600 {
+		t.Errorf("OpenPTYOwned got: %d,%d,%v want: %d,%d,%v", st.Uid, st.Gid, fi.Mode().Perm(), uid, gid, os.FileMode(0600))
+	}
+	if os.Getuid() != 0 {
+		if _, err := OpenPTYOwned(0, 0, 0600); !errors.Is(err, os.ErrPermission) {
+			t.Errorf("OpenPTYOwned(0, 0) without root got: %v want: %v", err, os.ErrPermission)
+		}
+	}
+}
+
+// TestOpenPTYWith tests the size and attributes being set on a new PTY.
+func TestOpenPTYWith(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	attrs, err := Attr(tty.Slave)
+	tty.Close()
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	attrs.Raw()
+	tty, err = OpenPTYWith(40, 132, &attrs)
+	if err != nil {
+		t.Fatalf("OpenPTYWith(40, 132) failed: %v", err)
+	}
+	if rows, cols, err := GetSize(tty.Slave); err != nil || rows != 40 || cols != 132 {
+		t.Errorf("OpenPTYWith Size got: %d,%d,%v want: 40,132,<nil>", rows, cols, err)
+	}
+	if got, err := Attr(tty.Slave); err != nil || got != attrs {
+		t.Errorf("OpenPTYWith Attr got: %v,%v want: %v,<nil>", got, err, attrs)
+	}
+	tty.Close()
+	tty, err = OpenPTYWith(24, 80, nil)
+	if err != nil {
+		t.Fatalf("OpenPTYWith(24, 80, nil) failed: %v", err)
+	}
+	defer tty.Close()
+	if got, err := Attr(tty.Slave); err != nil || got.Lflag&ICANON == 0 {
+		t.Errorf("OpenPTYWith nil attrs got: %v,%v want: cooked mode", got, err)
+	}
+	if _, err := OpenPTYWith(-1, 80, nil); err == nil {
+		t.Errorf("OpenPTYWith(-1, 80) got: <nil> want: invalid size error")
+	}
+}
+
+// TestOpenPTMX tests falling back to the other multiplexer devices.
+func TestOpenPTMX(t *testing.T) {
+	f, err := openPTMX("/nonexistent/ptmx", "/dev/ptmx")
+	if err != nil {
+		t.Fatalf("openPTMX with fallback failed: %v", err)
+	}
+	f.Close()
+	_, err = openPTMX("/nonexistent/ptmx", "/nonexistent/pts/ptmx")
+	for _, want := range []error{ErrNoPTMX, ErrNoPTY, os.ErrNotExist} {
+		if !errors.Is(err, want) {
+			t.Errorf("openPTMX with no devices got: %v want: errors.Is %v", err, want)
+		}
+	}
+	if _, err := openPTMX("/nonexistent/ptmx", "/dev/null/ptmx"); errors.Is(err, ErrNoPTMX) {
+		t.Errorf("openPTMX of non directory got: %v want: not %v", err, ErrNoPTMX)
+	}
+}
+
+// TestSetSlaveEcho tests echo of what's written to the Master.
+func TestSetSlaveEcho(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := tty.SetSlaveEcho(false); err != nil {
+		t.Fatalf("SetSlaveEcho(false) failed: %v", err)
+	}
+	if _, err := tty.Write([]byte("secret\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	b := make([]byte, 16)
+	n, err := tty.Slave.Read(b)
+	if err != nil || string(b[:n]) != "secret\n" {
+		t.Fatalf("Slave.Read got: %q,%v want: %q,<nil>", b[:n], err, "secret\n")
+	}
+	if err := tty.SetSlaveEcho(true); err != nil {
+		t.Fatalf("SetSlaveEcho(true) failed: %v", err)
+	}
+	if _, err := tty.Write([]byte("shown\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// Only the second line is echoed back.
+	b = make([]byte, len("shown\r\n"))
+	if _, err := io.ReadFull(tty, b); err != nil || string(b) != "shown\r\n" {
+		t.Errorf("Read echo got: %q,%v want: %q,<nil>", b, err, "shown\r\n")
+	}
+	master, err := OpenPTYMaster()
+	if err != nil {
+		t.Fatalf("OpenPTYMaster failed: %v", err)
+	}
+	defer master.Close()
+	if err := master.SetSlaveEcho(false); err != nil {
+		t.Fatalf("SetSlaveEcho(false) of master only PTY failed: %v", err)
+	}
+	if got, err := Attr(master.Master); err != nil || got.Lflag&ECHO != 0 {
+		t.Errorf("SetSlaveEcho(false) of master only PTY got Lflag: %o,%v want ECHO cleared", got.Lflag, err)
+	}
+	if err := (&PTY{}).SetSlaveEcho(true); err != ErrNilSlave {
+		t.Errorf("SetSlaveEcho with nil PTY files got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestDisableCloseOnExec tests the PTY staying open in a program started with exec.
+func TestDisableCloseOnExec(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	inherited := func() bool {
+		cmd := "test -e /proc/self/fd/" + strconv.Itoa(int(tty.Master.Fd())) + " && test -e /proc/self/fd/" + strconv.Itoa(int(tty.Slave.Fd()))
+		return exec.Command("/bin/sh", "-c", cmd).Run() == nil
+	}
+	if inherited() {
+		t.Errorf("PTY inherited before DisableCloseOnExec")
+	}
+	if err := tty.DisableCloseOnExec(); err != nil {
+		t.Fatalf("DisableCloseOnExec failed: %v", err)
+	}
+	if !inherited() {
+		t.Errorf("PTY not inherited after DisableCloseOnExec")
+	}
+	if err := (&PTY{}).DisableCloseOnExec(); err != nil {
+		t.Errorf("DisableCloseOnExec with nil PTY files got: %v want: <nil>", err)
+	}
+	if err := (*PTY)(nil).DisableCloseOnExec(); err != ErrNoPTY {
+		t.Errorf("DisableCloseOnExec of nil PTY got: %v want: %v", err, ErrNoPTY)
+	}
+}
+
+// TestRunPTY tests capturing the output of a program run on a PTY.
+func TestRunPTY(t *testing.T) {
+	out, err := RunPTY("/bin/sh", "-c", "test -t 1 && echo tty; stty size")
+	if err != nil {
+		t.Fatalf("RunPTY failed: %v output: %q", err, out)
+	}
+	rows, cols, serr := GetSize(os.Stdout)
+	if serr != nil || rows == 0 || cols == 0 {
+		rows, cols = defaultRows, defaultCols
+	}
+	if want := "tty\r\n" + strconv.Itoa(rows) + " " + strconv.Itoa(cols) + "\r\n"; string(out) != want {
+		t.Errorf("RunPTY got: %q want: %q", out, want)
+	}
+	big := strings.Repeat("x", 100000)
+	out, err = RunPTY("/bin/sh", "-c", "printf %s "+big+"; exit 2")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+		t.Errorf("RunPTY got: %v want: exit status 2", err)
+	}
+	if string(out) != big {
+		t.Errorf("RunPTY got: %d bytes want: %d", len(out), len(big))
+	}
+	if _, err := RunPTY("/nonexistent/program"); err == nil {
+		t.Errorf("RunPTY of missing program got: <nil> want: error")
+	}
+}
+
+// TestIsPTYClosed tests recognizing the error reading a Master with the Slave closed.
+func TestIsPTYClosed(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if _, err := tty.Slave.Write([]byte("bye\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := tty.CloseSlave(); err != nil {
+		t.Fatalf("CloseSlave failed: %v", err)
+	}
+	// ReadAll only returns once reading the Master fails, EOF is given as nil.
+	out, err := io.ReadAll(tty.Master)
+	if err != nil && !IsPTYClosed(err) {
+		t.Errorf("IsPTYClosed(%v) got: false want: true", err)
+	}
+	if got, want := string(out), "bye\r\n"; got != want {
+		t.Errorf("ReadAll got: %q want: %q", got, want)
+	}
+	for _, tst := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{io.EOF, true},
+		{syscall.EIO, true},
+		{os.ErrClosed, false},
+		{syscall.EBADF, false},
+	} {
+		if got := IsPTYClosed(tst.err); got != tst.want {
+			t.Errorf("IsPTYClosed(%v) got: %t want: %t", tst.err, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux
+
+package term
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Start starts cmd with the PTY Slave as stdin, stdout, stderr and controlling terminal.
+// The command is started in a new session. Other SysProcAttr settings of cmd are kept.
+// The Slave is left open in the parent, use StartAndCloseSlave if there's no further use for it.
+func (p *PTY) Start(cmd *exec.Cmd) error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	if p.Slave == nil {
+		return ErrNilSlave
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = p.Slave, p.Slave, p.Slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0 // Ctty is the fd in the child, stdin being the Slave.
+	return cmd.Start()
+}
+
+// StartAndCloseSlave starts cmd as Start does and then closes the Slave in the parent, the child
+// keeps its own copy. With no other copies left reading the Master gives an error matching
+// IsPTYClosed once the command and anything it started are done with the terminal.
+// If cmd can't be started the Slave is left open.
+func (p *PTY) StartAndCloseSlave(cmd *exec.Cmd) error {
+	if err := p.Start(cmd); err != nil {
+		return err
+	}
+	return p.CloseSlave()
+}
+
+// Run starts the program name with args on the PTY as Start does and returns its process.
+// Use Wait to wait for it to exit, only the last process started by Run is waited for.
+//
+//	p, err := term.OpenPTY()
+//	...
+//	if _, err := p.Run("/bin/sh", "-i"); err != nil {
+//		...
+//	}
+//	go io.Copy(os.Stdout, p)
+//	err = p.Wait()
+func (p *PTY) Run(name string, args ...string) (*os.Process, error) {
+	if p == nil {
+		return nil, ErrNoPTY
+	}
+	cmd := exec.Command(name, args...)
+	if err := p.Start(cmd); err != nil {
+		return nil, err
+	}
+	p.cmd = cmd
+	return cmd.Process, nil
+}
+
+// RunPTY runs the program name with args on a new PTY and returns everything it wrote once it exits.
+// Programs buffer or leave out colors when their output isn't a terminal, on a PTY they write as
+// they would for a user. The window size is the one of os.Stdout if it's a terminal, otherwise 24x80.
+// The PTY is in cooked mode so newlines come out as CR-NL. The output is read until every
+// process having the Slave open is done, the error is the one from Wait.
+func RunPTY(name string, args ...string) (output []byte, err error) {
+	rows, cols, err := GetSize(os.Stdout)
+	if err != nil || rows == 0 || cols == 0 {
+		rows, cols = defaultRows, defaultCols
+	}
+	p, err := OpenPTYWith(rows, cols, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer p.Close()
+	if _, err := p.Run(name, args...); err != nil {
+		return nil, err
+	}
+	// With the parent's copy of the Slave closed reading the Master ends once the child is done.
+	if err := p.CloseSlave(); err != nil {
+		p.cmd.Process.Kill()
+		p.Wait()
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, cerr := io.Copy(&buf, p.Master)
+	err = p.Wait()
+	if err == nil && cerr != nil && !IsPTYClosed(cerr) {
+		err = cerr
+	}
+	return buf.Bytes(), err
+}
+
+// Wait waits for the process started by Run to exit and releases its resources.
+// The error is *exec.ExitError if it didn't exit successfully, as for exec.Cmd Wait.
+// ErrNoProcess is returned if Run wasn't called.
+func (p *PTY) Wait() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	if p.cmd == nil {
+		return ErrNoProcess
+	}
+	return p.cmd.Wait()
+}
+
+// DisableCloseOnExec clears FD_CLOEXEC on the Master and Slave so they stay open in programs
+// started with exec, eg. a supervisor re-executing itself handing over the PTY.
+// Go opens all files with FD_CLOEXEC set, and os/exec only passes on stdin, stdout, stderr and
+// ExtraFiles, a PTY isn't inherited otherwise. A nil Master or Slave is skipped.
+func (p *PTY) DisableCloseOnExec() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	for _, f := range []*os.File{p.Master, p.Slave} {
+		if f == nil {
+			continue
+		}
+		if err := clearCloseOnExec(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearCloseOnExec clears the FD_CLOEXEC flag of f.
+func clearCloseOnExec(f *os.File) error {
+	fd := f.Fd()
+	flags, _, e := syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_GETFD, 0)
+	if e != 0 {
+		return os.NewSyscallError("fcntl", e)
+	}
+	if _, _, e := syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_SETFD, flags&^syscall.FD_CLOEXEC); e != 0 {
+		return os.NewSyscallError("fcntl", e)
+	}
+	return nil
+}
+
+// Resize sets the window size of the PTY to rows and cols and sends SIGWINCH to the foreground
+// process group of the terminal so whatever runs on it redraws, even if the size didn't change.
+// The size is set on the Slave, or on the Master for a PTY from OpenPTYMaster.
+func (p *PTY) Resize(rows, cols int) error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	f := p.Slave
+	if f == nil {
+		f = p.Master
+	}
+	if f == nil {
+		return ErrNilSlave
+	}
+	if err := SetSize(f, rows, cols); err != nil {
+		return err
+	}
+	// The Master can always be asked, the Slave only by processes having it as controlling terminal.
+	if p.Master == nil {
+		return nil
+	}
+	pgrp, err := Tcgetpgrp(p.Master)
+	if err != nil || pgrp <= 0 {
+		// Nothing running on the terminal to redraw.
+		return nil
+	}
+	return syscall.Kill(-pgrp, syscall.SIGWINCH)
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux
+
+package term
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// TestPTSNameOpen tests the name from PTSName can be opened as the slave of the PTY.
+func TestPTSNameOpen(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	if got := TerminalName(tty.Slave); got != name {
+		t.Errorf("PTSName got: %q want: %q", name, got)
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q) failed: %v", name, err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("x")); err != nil {
+		t.Fatalf("Write to %q failed: %v", name, err)
+	}
+	b := make([]byte, 1)
+	if _, err := tty.Master.Read(b); err != nil || b[0] != 'x' {
+		t.Errorf("Read from Master got: %q,%v want: %q,<nil>", b, err, "x")
+	}
+}
+
+// TestPTYEndToEnd opens a PTY, passes data from the Master to the Slave, resizes it and closes it.
+func TestPTYEndToEnd(t *testing.T) {
+	tty, err := OpenPTY()
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+		t.Skipf("no PTYs available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	if !Isatty(tty.Slave) {
+		t.Errorf("Isatty(Slave) got: false want: true")
+	}
+	if _, err := tty.Master.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write to Master failed: %v", err)
+	}
+	b := make([]byte, 16)
+	n, err := tty.Slave.Read(b)
+	if err != nil || string(b[:n]) != "hello\n" {
+		t.Errorf("Read from Slave got: %q,%v want: %q,<nil>", b[:n], err, "hello\n")
+	}
+	if err := tty.Resize(33, 101); err != nil {
+		t.Fatalf("Resize(33, 101) failed: %v", err)
+	}
+	if rows, cols, err := GetSize(tty.Slave); err != nil || rows != 33 || cols != 101 {
+		t.Errorf("GetSize after Resize got: %d,%d,%v want: 33,101,<nil>", rows, cols, err)
+	}
+	if rows, cols, err := GetSize(tty.Master); err != nil || rows != 33 || cols != 101 {
+		t.Errorf("GetSize of Master after Resize got: %d,%d,%v want: 33,101,<nil>", rows, cols, err)
+	}
+	if err := tty.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
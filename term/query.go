@@ -0,0 +1,155 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+// replyTimeout is how long the terminal is given to reply to a query.
+const replyTimeout = time.Second
+
+// st is the String Terminator ending DCS and OSC sequences, OSC ones can end in BEL too.
+const st = "\x1b\\"
+
+// Query puts the terminal f in raw mode, writes request and reads the reply up to and including
+// terminator. Reads are bounded the same way as in GetPassContext. This is what the query
+// functions of the package use, it's for asking about what they don't cover.
+// With BEL as terminator the reply ending in ST (ESC \) is taken too, terminals end OSC replies
+// either way. Anything typed before the reply came in is returned in front of it, replyBody skips it.
+// ErrNotATerminal is returned right away if f isn't a tty, ErrTimeout if no reply arrived within
+// timeout, eg. when nothing is on the other end. The terminal attributes are restored in all cases.
+//
+//	reply, err := term.Query(os.Stdout, "\x1b[?u", 'u', time.Second) // kitty keyboard flags
+func Query(f *os.File, request string, terminator byte, timeout time.Duration) ([]byte, error) {
+	if !Isatty(f) {
+		return nil, ErrNotATerminal
+	}
+	t, err := Attr(f)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Set(f)
+	raw := t
+	raw.Raw()
+	raw.Cc[VMIN] = 0
+	raw.Cc[VTIME] = byte(pollInterval / (100 * time.Millisecond))
+	if err := raw.Set(f); err != nil {
+		return nil, err
+	}
+	defer f.SetReadDeadline(time.Time{})
+	if _, err := f.Write([]byte(request)); err != nil {
+		return nil, err
+	}
+	var reply []byte
+	b := make([]byte, 1, 1)
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); {
+		f.SetReadDeadline(time.Now().Add(pollInterval))
+		_, err := f.Read(b)
+		if pollTimeout(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reply = append(reply, b[0])
+		if b[0] == terminator || (terminator == '\a' && bytes.HasSuffix(reply, []byte(st))) {
+			return reply, nil
+		}
+	}
+	return nil, ErrTimeout
+}
+
+// replyBody returns what follows the last start in the reply read by Query, leaving out the
+// terminator, one byte or ST, and the input typed before the reply. ok is false if start isn't in reply.
+func replyBody(reply []byte, start string) (body []byte, ok bool) {
+	end := len(reply) - 1
+	if bytes.HasSuffix(reply, []byte(st)) {
+		end = len(reply) - len(st)
+	}
+	i := bytes.LastIndex(reply[:max(end, 0)], []byte(start))
+	if i < 0 {
+		return nil, false
+	}
+	return reply[i+len(start) : end], true
+}
+
+// CursorPosition asks the terminal f where the cursor is using the ESC[6n device status report.
+// The row and col returned count from 1 for the top left corner.
+// ErrTimeout is returned if the terminal doesn't reply.
+func CursorPosition(f *os.File) (row, col int, err error) {
+	reply, err := Query(f, "\x1b[6n", 'R', replyTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	body, ok := replyBody(reply, "\x1b[")
+	if !ok {
+		return 0, 0, errors.New("cursor position reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	pos := bytes.Split(body, []byte(";"))
+	if len(pos) != 2 {
+		return 0, 0, errors.New("cursor position reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	if row, err = strconv.Atoi(string(pos[0])); err != nil {
+		return 0, 0, err
+	}
+	if col, err = strconv.Atoi(string(pos[1])); err != nil {
+		return 0, 0, err
+	}
+	return row, col, nil
+}
+
+// DeviceAttributes asks the terminal f for its primary device attributes using ESC[c and returns
+// the parameters of the ESC[?...c reply, eg. "64;1;2;4;6;22" where 64 is the conformance level
+// and the rest the features supported, 4 for sixel graphics and 22 for ANSI color.
+// ErrTimeout is returned if the terminal doesn't reply within timeout.
+func DeviceAttributes(f *os.File, timeout time.Duration) (string, error) {
+	reply, err := Query(f, "\x1b[c", 'c', timeout)
+	if err != nil {
+		return "", err
+	}
+	body, ok := replyBody(reply, "\x1b[?")
+	if !ok {
+		return "", errors.New("device attributes reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	return string(body), nil
+}
+
+// BackgroundColor asks the terminal f for its background color using the OSC 11 query.
+// The rgb:RRRR/GGGG/BBBB reply is scaled to 16 bits per component whatever number of hex digits
+// the terminal sends, eg. rgb:ff/ff/ff is white with r, g and b 0xffff.
+// The query ends in BEL, the reply can end in BEL or ST.
+// ErrTimeout is returned if the terminal doesn't reply within timeout.
+func BackgroundColor(f *os.File, timeout time.Duration) (r, g, b uint16, err error) {
+	reply, err := Query(f, "\x1b]11;?\a", '\a', timeout)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	body, ok := replyBody(reply, "\x1b]11;rgb:")
+	if !ok {
+		return 0, 0, 0, errors.New("background color reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	rgb := bytes.Split(body, []byte("/"))
+	if len(rgb) != 3 {
+		return 0, 0, 0, errors.New("background color reply: " + strconv.Quote(string(reply)) + " not valid")
+	}
+	var c [3]uint16
+	for n, hex := range rgb {
+		if len(hex) < 1 || len(hex) > 4 {
+			return 0, 0, 0, errors.New("background color reply: " + strconv.Quote(string(reply)) + " not valid")
+		}
+		v, err := strconv.ParseUint(string(hex), 16, 16)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		// Scale so the largest value of the digits sent becomes 0xffff.
+		c[n] = uint16(v * 0xffff / (1<<(4*len(hex)) - 1))
+	}
+	return c[0], c[1], c[2], nil
+}
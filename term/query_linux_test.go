@@ -0,0 +1,213 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// answerQuery replies with reply when the request shows up on the master of p.
+func answerQuery(p *PTY, request, reply string) {
+	out := readMaster(p)
+	go func() {
+		if strings.Contains(out.waitFor(request), request) {
+			p.Master.Write([]byte(reply))
+		}
+	}()
+}
+
+// TestQuery tests reading a reply up to the terminator.
+func TestQuery(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	want, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	answerQuery(p, "\x1b[?u", "\x1b[?1uleft")
+	if reply, err := Query(p.Slave, "\x1b[?u", 'u', replyTimeout); err != nil || string(reply) != "\x1b[?1u" {
+		t.Errorf("Query got: %q,%v want: %q,<nil>", reply, err, "\x1b[?1u")
+	}
+	if got, err := Attr(p.Slave); err != nil || got != want {
+		t.Errorf("Query left attributes: %v want: %v", got, want)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	start := time.Now()
+	if _, err := Query(w, "\x1b[?u", 'u', replyTimeout); err != ErrNotATerminal {
+		t.Errorf("Query of pipe got: %v want: %v", err, ErrNotATerminal)
+	}
+	if time.Since(start) > replyTimeout/2 {
+		t.Errorf("Query of pipe took: %v", time.Since(start))
+	}
+	if n, _ := Available(r); n != 0 {
+		t.Errorf("Query of pipe wrote: %d bytes want: 0", n)
+	}
+}
+
+// TestCursorPosition tests reading the cursor position reply.
+func TestCursorPosition(t *testing.T) {
+	tsts := []struct {
+		reply    string
+		row, col int
+		fail     bool
+	}{
+		{reply: "\x1b[12;34R", row: 12, col: 34},
+		{reply: "typed\x1b[1;1R", row: 1, col: 1},
+		{reply: "\x1b[12R", fail: true},
+		{reply: "\x1b[a;bR", fail: true},
+	}
+	for _, tst := range tsts {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		want, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		answerQuery(p, "\x1b[6n", tst.reply)
+		row, col, err := CursorPosition(p.Slave)
+		if tst.fail {
+			if err == nil {
+				t.Errorf("CursorPosition reply: %q got: <nil> want: error", tst.reply)
+			}
+		} else if err != nil || row != tst.row || col != tst.col {
+			t.Errorf("CursorPosition reply: %q got: %d,%d,%v want: %d,%d,<nil>", tst.reply, row, col, err, tst.row, tst.col)
+		}
+		if got, err := Attr(p.Slave); err != nil || got != want {
+			t.Errorf("CursorPosition left attributes: %v want: %v", got, want)
+		}
+		p.Close()
+	}
+}
+
+// TestCursorPositionTimeout tests giving up on a terminal not replying.
+func TestCursorPositionTimeout(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	if _, _, err := CursorPosition(p.Slave); err != ErrTimeout {
+		t.Errorf("CursorPosition got: %v want: %v", err, ErrTimeout)
+	}
+	f, err := donormfile("TestCursorPositionTimeout")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestCursorPositionTimeout\") failed: %v", err)
+	}
+	defer f.Close()
+	if _, _, err := CursorPosition(f); err != ErrNotATerminal {
+		t.Errorf("CursorPosition for normal file got: %v want: %v", err, ErrNotATerminal)
+	}
+}
+
+// TestDeviceAttributes tests reading the primary device attributes reply.
+func TestDeviceAttributes(t *testing.T) {
+	tsts := []struct {
+		reply string
+		want  string
+		fail  bool
+	}{
+		{reply: "\x1b[?64;1;2;4;6;22c", want: "64;1;2;4;6;22"},
+		{reply: "typed\x1b[?1;2c", want: "1;2"},
+		{reply: "\x1b[>0;10;1c", fail: true},
+	}
+	for _, tst := range tsts {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		want, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		answerQuery(p, "\x1b[c", tst.reply)
+		got, err := DeviceAttributes(p.Slave, replyTimeout)
+		if tst.fail {
+			if err == nil {
+				t.Errorf("DeviceAttributes reply: %q got: <nil> want: error", tst.reply)
+			}
+		} else if err != nil || got != tst.want {
+			t.Errorf("DeviceAttributes reply: %q got: %q,%v want: %q,<nil>", tst.reply, got, err, tst.want)
+		}
+		if got, err := Attr(p.Slave); err != nil || got != want {
+			t.Errorf("DeviceAttributes left attributes: %v want: %v", got, want)
+		}
+		p.Close()
+	}
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	if _, err := DeviceAttributes(p.Slave, 200*time.Millisecond); err != ErrTimeout {
+		t.Errorf("DeviceAttributes with no reply got: %v want: %v", err, ErrTimeout)
+	}
+}
+
+// TestBackgroundColor tests reading the background color reply.
+func TestBackgroundColor(t *testing.T) {
+	tsts := []struct {
+		reply   string
+		r, g, b uint16
+		fail    bool
+	}{
+		{reply: "\x1b]11;rgb:ffff/8000/0000\a", r: 0xffff, g: 0x8000, b: 0},
+		{reply: "\x1b]11;rgb:ff/80/00\a", r: 0xffff, g: 0x8080, b: 0},
+		{reply: "typed\x1b]11;rgb:f/0/1\a", r: 0xffff, g: 0, b: 0x1111},
+		{reply: "\x1b]11;rgb:0000/8000/ffff\x1b\\", r: 0, g: 0x8000, b: 0xffff},
+		{reply: "typed\x1b\x1b]11;rgb:ff/ff/ff\x1b\\", r: 0xffff, g: 0xffff, b: 0xffff},
+		{reply: "\x1b]11;rgb:ff/80\x1b\\", fail: true},
+		{reply: "\x1b]11;rgb:ff/80\a", fail: true},
+		{reply: "\x1b]11;rgb:fffff/0/0\a", fail: true},
+		{reply: "\x1b]11;rgb:xx/00/00\a", fail: true},
+		{reply: "\x1b]11;#ffffff\a", fail: true},
+	}
+	for _, tst := range tsts {
+		p, err := OpenPTY()
+		if err != nil {
+			t.Fatalf("OpenPTY failed: %v", err)
+		}
+		want, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		answerQuery(p, "\x1b]11;?\a", tst.reply)
+		r, g, b, err := BackgroundColor(p.Slave, replyTimeout)
+		if tst.fail {
+			if err == nil {
+				t.Errorf("BackgroundColor reply: %q got: <nil> want: error", tst.reply)
+			}
+		} else if err != nil || r != tst.r || g != tst.g || b != tst.b {
+			t.Errorf("BackgroundColor reply: %q got: %#x,%#x,%#x,%v want: %#x,%#x,%#x,<nil>", tst.reply, r, g, b, err, tst.r, tst.g, tst.b)
+		}
+		if got, err := Attr(p.Slave); err != nil || got != want {
+			t.Errorf("BackgroundColor left attributes: %v want: %v", got, want)
+		}
+		p.Close()
+	}
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	if _, _, _, err := BackgroundColor(p.Slave, 200*time.Millisecond); err != ErrTimeout {
+		t.Errorf("BackgroundColor with no reply got: %v want: %v", err, ErrTimeout)
+	}
+}
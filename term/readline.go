@@ -0,0 +1,172 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// ReadLine reads a line from the terminal f with simple editing, prompt is written first.
+// The terminal is in raw mode for the read and restored after. The keys handled are
+//
+//	Left, Right, Ctrl-B, Ctrl-F  move the cursor a rune
+//	Home, End, Ctrl-A, Ctrl-E    move the cursor to the start or end of the line
+//	Backspace, Delete            remove the rune before or under the cursor
+//	Ctrl-U, Ctrl-K               remove everything before or from the cursor
+//	Up, Down, Ctrl-P, Ctrl-N     go through history, the oldest entry first
+//	Enter                        returns the line
+//	Ctrl-C                       returns ErrInterrupted
+//	Ctrl-D                       io.EOF on an empty line, otherwise the same as Delete
+//
+// The line is redrawn after every change, it's expected to fit on one row with every rune one
+// column wide. history isn't changed, add the line returned to it for the next call.
+func ReadLine(f *os.File, prompt string, history []string) (string, error) {
+	restore, err := MakeRaw(f)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+	e := lineEditor{f: f, prompt: prompt, history: history, hist: len(history)}
+	if err := e.redraw(); err != nil {
+		return "", err
+	}
+	kr := NewKeyReader(f)
+	for {
+		k, err := kr.ReadKey()
+		if err != nil {
+			return "", err
+		}
+		done, err := e.handle(k)
+		if done || err != nil {
+			f.Write([]byte("\r\n"))
+			return string(e.line), err
+		}
+		if err := e.redraw(); err != nil {
+			return "", err
+		}
+	}
+}
+
+// lineEditor is the state of a ReadLine.
+type lineEditor struct {
+	f       *os.File
+	prompt  string
+	line    []rune   // line being edited
+	pos     int      // pos of the cursor in line
+	history []string // history to go through with Up and Down
+	hist    int      // hist index in history of the line shown, len(history) for the new line
+	draft   []rune   // draft new line kept while going through history
+}
+
+// handle does what key k does, done is set once the line is ready.
+func (e *lineEditor) handle(k Key) (done bool, err error) {
+	if k.Mod == ModCtrl && k.Special == KeyRune {
+		switch k.Rune {
+		case 'a':
+			k = Key{Special: KeyHome}
+		case 'e':
+			k = Key{Special: KeyEnd}
+		case 'b':
+			k = Key{Special: KeyLeft}
+		case 'f':
+			k = Key{Special: KeyRight}
+		case 'p':
+			k = Key{Special: KeyUp}
+		case 'n':
+			k = Key{Special: KeyDown}
+		case 'h':
+			k = Key{Special: KeyBackspace}
+		case 'j', 'm':
+			k = Key{Special: KeyEnter}
+		case 'c':
+			e.line = e.line[:0]
+			return true, ErrInterrupted
+		case 'd':
+			if len(e.line) == 0 {
+				return true, io.EOF
+			}
+			k = Key{Special: KeyDelete}
+		case 'u':
+			e.line = append(e.line[:0], e.line[e.pos:]...)
+			e.pos = 0
+			return false, nil
+		case 'k':
+			e.line = e.line[:e.pos]
+			return false, nil
+		default:
+			return false, nil
+		}
+	}
+	switch k.Special {
+	case KeyRune:
+		if k.Mod&(ModCtrl|ModAlt) != 0 {
+			return false, nil
+		}
+		e.line = append(e.line, 0)
+		copy(e.line[e.pos+1:], e.line[e.pos:])
+		e.line[e.pos] = k.Rune
+		e.pos++
+	case KeyEnter:
+		return true, nil
+	case KeyBackspace:
+		if e.pos > 0 {
+			e.line = append(e.line[:e.pos-1], e.line[e.pos:]...)
+			e.pos--
+		}
+	case KeyDelete:
+		if e.pos < len(e.line) {
+			e.line = append(e.line[:e.pos], e.line[e.pos+1:]...)
+		}
+	case KeyLeft:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case KeyRight:
+		if e.pos < len(e.line) {
+			e.pos++
+		}
+	case KeyHome:
+		e.pos = 0
+	case KeyEnd:
+		e.pos = len(e.line)
+	case KeyUp:
+		if e.hist > 0 {
+			if e.hist == len(e.history) {
+				e.draft = e.line
+			}
+			e.hist--
+			e.show([]rune(e.history[e.hist]))
+		}
+	case KeyDown:
+		if e.hist < len(e.history) {
+			e.hist++
+			if e.hist == len(e.history) {
+				e.show(e.draft)
+			} else {
+				e.show([]rune(e.history[e.hist]))
+			}
+		}
+	}
+	return false, nil
+}
+
+// show replaces the line with a copy of line, the cursor goes to the end.
+func (e *lineEditor) show(line []rune) {
+	e.line = append([]rune(nil), line...)
+	e.pos = len(e.line)
+}
+
+// redraw writes the prompt and line over the current row, erasing what's left of the old line,
+// and puts the cursor back at pos.
+func (e *lineEditor) redraw() error {
+	s := "\r" + e.prompt + string(e.line) + CSI + "K"
+	if back := len(e.line) - e.pos; back > 0 {
+		s += CSI + strconv.Itoa(back) + "D"
+	}
+	_, err := e.f.Write([]byte(s))
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestReadLine tests editing a line and going through history.
+func TestReadLine(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	want, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	out := readMaster(tty)
+	history := []string{"first", "second"}
+	tsts := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"hello\r", "hello", nil},
+		{"wrld\x1b[D\x1b[D\x1b[Do\r", "world", nil},
+		{"abc\x7f\x7fx\x1b[Hy\x1b[Fz\r", "yaxz", nil},
+		{"abc\x1b[H\x1b[3~\x05d\r", "bcd", nil},
+		{"abc\x02\x02\x0b\x01X\r", "Xa", nil},
+		{"\x1b[A\r", "second", nil},
+		{"\x1b[A\x1b[A\x1b[A!\r", "first!", nil},
+		{"new\x1b[A\x1b[B\r", "new", nil},
+		{"héllo\x7f\x7f\x7f\r", "hé", nil},
+		{"gone\x03", "", ErrInterrupted},
+		{"\x04", "", io.EOF},
+		{"ab\x01\x04\r", "b", nil},
+	}
+	for _, tst := range tsts {
+		out.reset()
+		go func() {
+			if res := out.waitFor("> "); strings.Contains(res, "> ") {
+				tty.Master.Write([]byte(tst.in))
+			}
+		}()
+		got, err := ReadLine(tty.Slave, "> ", history)
+		if got != tst.want || err != tst.err {
+			t.Errorf("ReadLine(%q) got: %q,%v want: %q,%v", tst.in, got, err, tst.want, tst.err)
+		}
+		if got, err := Attr(tty.Slave); err != nil || got != want {
+			t.Errorf("ReadLine(%q) left attributes: %v want: %v", tst.in, got, want)
+		}
+		// The line ends with CR-NL, nothing from this line is left once it's read.
+		out.waitFor("\r\n")
+	}
+	if history[0] != "first" || history[1] != "second" {
+		t.Errorf("ReadLine changed history: %q", history)
+	}
+	out.reset()
+	go func() {
+		if res := out.waitFor("> "); strings.Contains(res, "> ") {
+			tty.Master.Write([]byte("ab\x1b[D"))
+			out.waitFor("\x1b[1D")
+			tty.Master.Write([]byte("\r"))
+		}
+	}()
+	if _, err := ReadLine(tty.Slave, "> ", nil); err != nil {
+		t.Fatalf("ReadLine failed: %v", err)
+	}
+	if res := out.waitFor("\r\n"); res != "\r> \x1b[K\r> a\x1b[K\r> ab\x1b[K\r> ab\x1b[K\x1b[1D\r\n" {
+		t.Errorf("ReadLine redraw got: %q want: %q", res, "\r> \x1b[K\r> a\x1b[K\r> ab\x1b[K\r> ab\x1b[K\x1b[1D\r\n")
+	}
+}
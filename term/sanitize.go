@@ -0,0 +1,109 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// SanitizeForTerminal makes s safe to print on a terminal by replacing the control characters,
+// other than newline and tab, so they can't start escape sequences. C0 controls and DEL are shown
+// in caret notation like ESC as ^[, C1 controls and bytes that aren't valid UTF-8 as <0xNN>.
+func SanitizeForTerminal(s string) string {
+	b, _ := appendSanitized(nil, []byte(s), true)
+	return string(b)
+}
+
+// appendSanitized appends p to dst with the control characters replaced as by SanitizeForTerminal.
+// Unless final is set an incomplete rune at the end of p is left, the number of bytes of p
+// done is returned.
+func appendSanitized(dst, p []byte, final bool) ([]byte, int) {
+	i := 0
+	for i < len(p) {
+		c := p[i]
+		switch {
+		case c == '\n' || c == '\t':
+			dst = append(dst, c)
+		case c < 0x20:
+			dst = append(dst, '^', c+0x40)
+		case c == 0x7f:
+			dst = append(dst, '^', '?')
+		case c < utf8.RuneSelf:
+			dst = append(dst, c)
+		default:
+			if !final && !utf8.FullRune(p[i:]) {
+				return dst, i
+			}
+			r, n := utf8.DecodeRune(p[i:])
+			switch {
+			case r == utf8.RuneError && n == 1:
+				dst = appendHexByte(dst, c)
+			case r >= 0x80 && r <= 0x9f:
+				dst = appendHexByte(dst, byte(r))
+			default:
+				dst = append(dst, p[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+		i++
+	}
+	return dst, i
+}
+
+// hexDigits are the digits used by appendHexByte.
+const hexDigits = "0123456789ABCDEF"
+
+// appendHexByte appends c as <0xNN>.
+func appendHexByte(dst []byte, c byte) []byte {
+	return append(dst, '<', '0', 'x', hexDigits[c>>4], hexDigits[c&0xf], '>')
+}
+
+// safeWriter is the io.Writer returned by SafeWriter.
+type safeWriter struct {
+	w       io.Writer
+	pending []byte
+	buf     []byte
+}
+
+// SafeWriter returns a writer sanitizing everything written to it as SanitizeForTerminal does
+// before passing it on to w. A rune split over writes is held back until the rest of it arrives.
+// The writer has a Flush method writing out what's held back as <0xNN>, call it when done so an
+// incomplete rune at the end isn't lost:
+//
+//	sw := term.SafeWriter(os.Stdout)
+//	defer sw.(interface{ Flush() error }).Flush()
+//
+// It's not safe for concurrent use.
+func SafeWriter(w io.Writer) io.Writer {
+	return &safeWriter{w: w}
+}
+
+// Write implements the io.Writer interface, on success len(p) is returned.
+func (sw *safeWriter) Write(p []byte) (int, error) {
+	in := p
+	if len(sw.pending) > 0 {
+		in = append(sw.pending, p...)
+	}
+	var n int
+	sw.buf, n = appendSanitized(sw.buf[:0], in, false)
+	sw.pending = append(sw.pending[:0:0], in[n:]...)
+	if _, err := sw.w.Write(sw.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out the bytes of an incomplete rune held back by Write, replaced as invalid UTF-8.
+func (sw *safeWriter) Flush() error {
+	if len(sw.pending) == 0 {
+		return nil
+	}
+	sw.buf, _ = appendSanitized(sw.buf[:0], sw.pending, true)
+	sw.pending = sw.pending[:0]
+	_, err := sw.w.Write(sw.buf)
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestSanitizeForTerminal tests replacing the control characters.
+func TestSanitizeForTerminal(t *testing.T) {
+	tsts := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"line\n\tindented", "line\n\tindented"},
+		{"\x1b]2;evil\a", "^[]2;evil^G"},
+		{"bell\x00\x7f\r", "bell^@^?^M"},
+		{"8bit \u009b31m", "8bit <0x9B>31m"},
+		{"bad \x9b\xff", "bad <0x9B><0xFF>"},
+		{"välid ✓", "välid ✓"},
+	}
+	for _, tst := range tsts {
+		if got := SanitizeForTerminal(tst.in); got != tst.want {
+			t.Errorf("SanitizeForTerminal(%q) got: %q want: %q", tst.in, got, tst.want)
+		}
+	}
+}
+
+// TestSafeWriter tests sanitizing writes, also with runes split over them.
+func TestSafeWriter(t *testing.T) {
+	var out bytes.Buffer
+	sw := SafeWriter(&out)
+	in := []byte("\x1b[2J✓ \u009b")
+	for i := range in {
+		if n, err := sw.Write(in[i : i+1]); err != nil || n != 1 {
+			t.Fatalf("Write got: %d,%v want: 1,<nil>", n, err)
+		}
+	}
+	if got, want := out.String(), "^[[2J✓ <0x9B>"; got != want {
+		t.Errorf("SafeWriter wrote: %q want: %q", got, want)
+	}
+}
+
+// TestSafeWriterFlush tests Flush writing out an incomplete rune left at the end.
+func TestSafeWriterFlush(t *testing.T) {
+	var out bytes.Buffer
+	sw := SafeWriter(&out)
+	flusher := sw.(interface{ Flush() error })
+	if err := flusher.Flush(); err != nil || out.Len() != 0 {
+		t.Errorf("Flush with nothing held back got: %q,%v want: \"\",<nil>", out.String(), err)
+	}
+	if _, err := sw.Write([]byte("end \xe2\x9c")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got, want := out.String(), "end "; got != want {
+		t.Errorf("SafeWriter before Flush wrote: %q want: %q", got, want)
+	}
+	if err := flusher.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got, want := out.String(), "end <0xE2><0x9C>"; got != want {
+		t.Errorf("SafeWriter after Flush wrote: %q want: %q", got, want)
+	}
+	if _, err := sw.Write([]byte("\x93")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got, want := out.String(), "end <0xE2><0x9C><0x93>"; got != want {
+		t.Errorf("SafeWriter after Flush and Write wrote: %q want: %q", got, want)
+	}
+}
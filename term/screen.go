@@ -0,0 +1,126 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Alternate screen sequences.
+const (
+	AltScreenOn  = CSI + "?1049h" // AltScreenOn switches to the alternate screen saving the cursor
+	AltScreenOff = CSI + "?1049l" // AltScreenOff switches back to the normal screen restoring the cursor
+)
+
+// EnterAltScreen switches the terminal f to the alternate screen.
+// Full screen programs use it so the shell output is back as it was when they exit.
+func EnterAltScreen(f *os.File) error {
+	_, err := f.Write([]byte(AltScreenOn))
+	return err
+}
+
+// ExitAltScreen switches the terminal f back to the normal screen.
+func ExitAltScreen(f *os.File) error {
+	_, err := f.Write([]byte(AltScreenOff))
+	return err
+}
+
+// WithAltScreen runs fn on the alternate screen of the terminal f.
+// The normal screen is switched back to when fn returns, also when it panics.
+//
+//	defer term.Guard(os.Stdin)()
+//	err := term.WithAltScreen(os.Stdout, func() error {
+//		return draw()
+//	})
+func WithAltScreen(f *os.File, fn func() error) (err error) {
+	if err := EnterAltScreen(f); err != nil {
+		return err
+	}
+	defer func() {
+		if exitErr := ExitAltScreen(f); err == nil {
+			err = exitErr
+		}
+	}()
+	return fn()
+}
+
+// Screen and cursor sequences.
+const (
+	ClearAll   = CSI + "2J"   // ClearAll erases the whole screen, the cursor stays where it is
+	CursorHome = CSI + "H"    // CursorHome moves the cursor to the top left corner
+	CursorHide = CSI + "?25l" // CursorHide makes the cursor invisible
+	CursorShow = CSI + "?25h" // CursorShow makes the cursor visible again
+)
+
+// ClearScreen erases the screen of terminal f and moves the cursor to the top left corner.
+func ClearScreen(f *os.File) error {
+	_, err := f.Write([]byte(CursorHome + ClearAll))
+	return err
+}
+
+// MoveCursor moves the cursor of terminal f to row and col, counting from 1 for the top left
+// corner same as CursorPosition. The terminal keeps the cursor on the screen if they're past the edge.
+func MoveCursor(f *os.File, row, col int) error {
+	if row < 1 || col < 1 {
+		return errors.New("cursor: " + strconv.Itoa(row) + ";" + strconv.Itoa(col) + " not a valid position")
+	}
+	_, err := f.Write([]byte(CSI + strconv.Itoa(row) + ";" + strconv.Itoa(col) + "H"))
+	return err
+}
+
+// HideCursor makes the cursor of terminal f invisible.
+// Use ShowCursor before exiting, the terminal doesn't bring it back by itself.
+func HideCursor(f *os.File) error {
+	_, err := f.Write([]byte(CursorHide))
+	return err
+}
+
+// ShowCursor makes the cursor of terminal f visible again.
+func ShowCursor(f *os.File) error {
+	_, err := f.Write([]byte(CursorShow))
+	return err
+}
+
+// Window title sequences, the title goes between OSC2 and BEL.
+const (
+	OSC2      = "\x1b]2;"     // OSC2 starts setting the window title
+	BEL       = "\a"          // BEL ends an OSC sequence
+	TitlePush = CSI + "22;0t" // TitlePush saves the window title on the terminal title stack
+	TitlePop  = CSI + "23;0t" // TitlePop restores the title last saved by TitlePush
+)
+
+// SetTitle sets the window title of terminal f.
+// Control characters are dropped from title so it can't end the sequence and start another one.
+func SetTitle(f *os.File, title string) error {
+	_, err := f.Write([]byte(OSC2 + stripControl(title) + BEL))
+	return err
+}
+
+// PushTitle saves the window title of terminal f, PopTitle brings it back.
+// Terminals without a title stack ignore both.
+func PushTitle(f *os.File) error {
+	_, err := f.Write([]byte(TitlePush))
+	return err
+}
+
+// PopTitle restores the window title of terminal f saved by PushTitle.
+func PopTitle(f *os.File) error {
+	_, err := f.Write([]byte(TitlePop))
+	return err
+}
+
+// stripControl returns s with the C0, DEL and C1 control characters removed.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// screenOutput runs fn with the write end of a pipe and returns everything written to it.
+func screenOutput(t *testing.T, fn func(w *os.File)) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	func() {
+		defer w.Close()
+		fn(w)
+	}()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	return string(got)
+}
+
+// TestAltScreen tests the sequences written to enter and exit the alternate screen.
+func TestAltScreen(t *testing.T) {
+	got := screenOutput(t, func(w *os.File) {
+		if err := EnterAltScreen(w); err != nil {
+			t.Errorf("EnterAltScreen failed: %v", err)
+		}
+		if err := ExitAltScreen(w); err != nil {
+			t.Errorf("ExitAltScreen failed: %v", err)
+		}
+	})
+	if want := "\x1b[?1049h\x1b[?1049l"; got != want {
+		t.Errorf("Enter/ExitAltScreen wrote: %q want: %q", got, want)
+	}
+}
+
+// TestWithAltScreen tests the alternate screen being left when fn returns an error or panics.
+func TestWithAltScreen(t *testing.T) {
+	errFn := errors.New("fn failed")
+	got := screenOutput(t, func(w *os.File) {
+		if err := WithAltScreen(w, func() error {
+			w.Write([]byte("draw"))
+			return errFn
+		}); err != errFn {
+			t.Errorf("WithAltScreen got: %v want: %v", err, errFn)
+		}
+	})
+	if want := "\x1b[?1049hdraw\x1b[?1049l"; got != want {
+		t.Errorf("WithAltScreen wrote: %q want: %q", got, want)
+	}
+	got = screenOutput(t, func(w *os.File) {
+		defer func() {
+			if r := recover(); r != "crash" {
+				t.Errorf("WithAltScreen panic got: %v want: crash", r)
+			}
+		}()
+		WithAltScreen(w, func() error {
+			panic("crash")
+		})
+	})
+	if want := "\x1b[?1049h\x1b[?1049l"; got != want {
+		t.Errorf("WithAltScreen on panic wrote: %q want: %q", got, want)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	r.Close()
+	w.Close()
+	called := false
+	if err := WithAltScreen(w, func() error { called = true; return nil }); err == nil || called {
+		t.Errorf("WithAltScreen on closed file got: %v called: %t want: error called: false", err, called)
+	}
+}
+
+// TestCursor tests the sequences written to clear the screen, hide, show and move the cursor.
+func TestCursor(t *testing.T) {
+	got := screenOutput(t, func(w *os.File) {
+		for _, fn := range []func(*os.File) error{ClearScreen, HideCursor, ShowCursor} {
+			if err := fn(w); err != nil {
+				t.Errorf("writing sequence failed: %v", err)
+			}
+		}
+		if err := MoveCursor(w, 5, 12); err != nil {
+			t.Errorf("MoveCursor(5,12) failed: %v", err)
+		}
+		if err := MoveCursor(w, 0, 1); err == nil {
+			t.Errorf("MoveCursor(0,1) got: <nil> want: not a valid position")
+		}
+	})
+	if want := "\x1b[H\x1b[2J\x1b[?25l\x1b[?25h\x1b[5;12H"; got != want {
+		t.Errorf("cursor sequences wrote: %q want: %q", got, want)
+	}
+}
+
+// TestTitle tests setting the title with the control characters stripped, and saving and restoring it.
+func TestTitle(t *testing.T) {
+	got := screenOutput(t, func(w *os.File) {
+		if err := PushTitle(w); err != nil {
+			t.Errorf("PushTitle failed: %v", err)
+		}
+		if err := SetTitle(w, "build: ok\a\x1b]2;evil\x1b\\ \u009bdone ✓"); err != nil {
+			t.Errorf("SetTitle failed: %v", err)
+		}
+		if err := PopTitle(w); err != nil {
+			t.Errorf("PopTitle failed: %v", err)
+		}
+	})
+	if want := "\x1b[22;0t\x1b]2;build: ok]2;evil\\ done ✓\a\x1b[23;0t"; got != want {
+		t.Errorf("title sequences wrote: %q want: %q", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"sync"
+)
+
+// Scrollback keeps the last bytes written to it in a fixed size ring, eg. the output of the program
+// on a PTY to replay to a client reattaching to the session.
+// It's safe for concurrent use.
+//
+//	sb := term.NewScrollback(64 << 10)
+//	go io.Copy(io.MultiWriter(sb, client), pty)
+//	...
+//	sb.WriteTo(newClient)
+type Scrollback struct {
+	mu   sync.Mutex
+	buf  []byte
+	end  int  // end where the next byte goes
+	full bool // full the ring has wrapped, the oldest byte is at end
+}
+
+// NewScrollback returns a Scrollback keeping the last size bytes, the buffer is allocated once here.
+// With a size below 1 nothing is kept.
+func NewScrollback(size int) *Scrollback {
+	if size < 0 {
+		size = 0
+	}
+	return &Scrollback{buf: make([]byte, size)}
+}
+
+// Write implements the io.Writer interface, all of p is taken but only the last bytes are kept.
+func (sb *Scrollback) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	n := len(p)
+	size := len(sb.buf)
+	if size == 0 {
+		return n, nil
+	}
+	if len(p) >= size {
+		copy(sb.buf, p[len(p)-size:])
+		sb.end = 0
+		sb.full = true
+		return n, nil
+	}
+	c := copy(sb.buf[sb.end:], p)
+	if c < len(p) {
+		copy(sb.buf, p[c:])
+		sb.full = true
+	}
+	sb.end = (sb.end + len(p)) % size
+	if sb.end == 0 {
+		sb.full = true
+	}
+	return n, nil
+}
+
+// parts returns what is kept, oldest first, as the two parts of the ring. sb.mu must be held.
+func (sb *Scrollback) parts() ([]byte, []byte) {
+	if !sb.full {
+		return sb.buf[:sb.end], nil
+	}
+	return sb.buf[sb.end:], sb.buf[:sb.end]
+}
+
+// Len returns the number of bytes kept.
+func (sb *Scrollback) Len() int {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	first, second := sb.parts()
+	return len(first) + len(second)
+}
+
+// Bytes returns a copy of the bytes kept, oldest first.
+func (sb *Scrollback) Bytes() []byte {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	first, second := sb.parts()
+	b := make([]byte, 0, len(first)+len(second))
+	return append(append(b, first...), second...)
+}
+
+// WriteTo implements the io.WriterTo interface writing the bytes kept to w, oldest first.
+// Writes to sb wait until it's done.
+func (sb *Scrollback) WriteTo(w io.Writer) (int64, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	var total int64
+	first, second := sb.parts()
+	for _, part := range [][]byte{first, second} {
+		if len(part) == 0 {
+			continue
+		}
+		n, err := w.Write(part)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
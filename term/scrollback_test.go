@@ -0,0 +1,52 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestScrollback tests keeping the last bytes written.
+func TestScrollback(t *testing.T) {
+	tsts := []struct {
+		size   int
+		writes []string
+		want   string
+	}{
+		{8, nil, ""},
+		{8, []string{"abc"}, "abc"},
+		{8, []string{"abc", "defgh"}, "abcdefgh"},
+		{8, []string{"abcdef", "ghij"}, "cdefghij"},
+		{8, []string{"abcdefghijklmnop"}, "ijklmnop"},
+		{8, []string{"abc", "defghijklmnop", "q"}, "jklmnopq"},
+		{3, []string{"a", "b", "c", "d", "e"}, "cde"},
+		{0, []string{"abc"}, ""},
+		{-1, []string{"abc"}, ""},
+	}
+	for _, tst := range tsts {
+		sb := NewScrollback(tst.size)
+		for _, w := range tst.writes {
+			if n, err := sb.Write([]byte(w)); err != nil || n != len(w) {
+				t.Errorf("Write(%q) got: %d,%v want: %d,<nil>", w, n, err, len(w))
+			}
+		}
+		if got := string(sb.Bytes()); got != tst.want {
+			t.Errorf("NewScrollback(%d) writes: %q Bytes got: %q want: %q", tst.size, tst.writes, got, tst.want)
+		}
+		if got := sb.Len(); got != len(tst.want) {
+			t.Errorf("NewScrollback(%d) writes: %q Len got: %d want: %d", tst.size, tst.writes, got, len(tst.want))
+		}
+		var out bytes.Buffer
+		if n, err := sb.WriteTo(&out); err != nil || n != int64(len(tst.want)) || out.String() != tst.want {
+			t.Errorf("NewScrollback(%d) writes: %q WriteTo got: %q,%d,%v want: %q,%d,<nil>", tst.size, tst.writes, out.String(), n, err, tst.want, len(tst.want))
+		}
+	}
+	sb := NewScrollback(16)
+	p := []byte("0123456789")
+	if allocs := testing.AllocsPerRun(100, func() { sb.Write(p) }); allocs != 0 {
+		t.Errorf("Write allocations got: %v want: 0", allocs)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Parity is the parity bit setting of a serial line.
+type Parity int
+
+// Parity settings for SetParity.
+const (
+	ParityNone Parity = iota // ParityNone no parity bit
+	ParityEven               // ParityEven even parity
+	ParityOdd                // ParityOdd odd parity
+)
+
+// String implements the Stringer interface.
+func (p Parity) String() string {
+	switch p {
+	case ParityNone:
+		return "none"
+	case ParityEven:
+		return "even"
+	case ParityOdd:
+		return "odd"
+	}
+	return "Parity(" + strconv.Itoa(int(p)) + ")"
+}
+
+// dataBits maps the number of data bits to the CSIZE character sizes.
+var dataBits = map[int]uint32{5: CS5, 6: CS6, 7: CS7, 8: CS8}
+
+// SetParity sets the parity bit of terminal t, any value other than ParityEven and ParityOdd turns it off.
+// Only the bit sent is set, to have parity errors on input checked set INPCK in Iflag too.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetParity(p Parity) {
+	t.Cflag &^= PARENB | PARODD
+	switch p {
+	case ParityEven:
+		t.Cflag |= PARENB
+	case ParityOdd:
+		t.Cflag |= PARENB | PARODD
+	}
+}
+
+// SetDataBits sets the number of data bits in a character of terminal t, 5 to 8.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetDataBits(n int) error {
+	cs, ok := dataBits[n]
+	if !ok {
+		return errors.New("data bits: " + strconv.Itoa(n) + " not a valid size 5-8")
+	}
+	t.Cflag = t.Cflag&^CSIZE | cs
+	return nil
+}
+
+// SetStopBits sets the number of stop bits of terminal t, 1 or 2.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetStopBits(n int) error {
+	switch n {
+	case 1:
+		t.Cflag &^= CSTOPB
+	case 2:
+		t.Cflag |= CSTOPB
+	default:
+		return errors.New("stop bits: " + strconv.Itoa(n) + " not valid, 1 or 2")
+	}
+	return nil
+}
+
+// Configure sets the line settings of terminal t in one go, eg. 115200 8N1 is
+//
+//	t.Configure(115200, 8, term.ParityNone, 1)
+//
+// Nothing is changed if any of the settings isn't valid.
+// Only t is changed, use Set to apply it.
+func (t *Termios) Configure(baud, dataBits int, parity Parity, stopBits int) error {
+	if parity < ParityNone || parity > ParityOdd {
+		return errors.New("parity: " + parity.String() + " not a valid parity")
+	}
+	nt := *t
+	if err := nt.SetSpeed(baud); err != nil {
+		return err
+	}
+	if err := nt.SetDataBits(dataBits); err != nil {
+		return err
+	}
+	if err := nt.SetStopBits(stopBits); err != nil {
+		return err
+	}
+	nt.SetParity(parity)
+	*t = nt
+	return nil
+}
+
+// OpenSerial opens the serial device path, eg. /dev/ttyUSB0, set up for 8N1 at baud in raw mode
+// with no flow control. Reads block until at least one byte is available, modem control lines
+// are ignored so there's no waiting for carrier. The device is opened with O_NONBLOCK so the open
+// doesn't wait for carrier either, it is cleared once CLOCAL is set. The device doesn't become
+// the controlling terminal. The attributes applied are returned with the file.
+func OpenSerial(path string, baud int) (*os.File, *Termios, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := Attr(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	t.Raw()
+	if err := t.Configure(baud, 8, ParityNone, 1); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	t.SetSoftwareFlow(false)
+	t.SetHardwareFlow(false)
+	t.Iflag &^= IXANY | INPCK
+	t.Cflag |= CREAD | CLOCAL
+	if err := t.Set(f); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if err := setBlocking(f); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, &t, nil
+}
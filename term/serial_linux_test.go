@@ -0,0 +1,59 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"syscall"
+	"testing"
+)
+
+// TestOpenSerial tests opening a PTY Slave as a serial device.
+func TestOpenSerial(t *testing.T) {
+	tty, err := OpenPTYMaster()
+	if err != nil {
+		t.Fatalf("OpenPTYMaster failed: %v", err)
+	}
+	defer tty.Close()
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	f, applied, err := OpenSerial(name, 115200)
+	if err != nil {
+		t.Fatalf("OpenSerial(%q, 115200) failed: %v", name, err)
+	}
+	defer f.Close()
+	// Checked before anything calls Fd, that sets blocking mode too.
+	rc, err := f.SyscallConn()
+	if err != nil {
+		t.Fatalf("SyscallConn failed: %v", err)
+	}
+	var flags uintptr
+	rc.Control(func(fd uintptr) {
+		flags, _, _ = syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_GETFL, 0)
+	})
+	if flags&syscall.O_NONBLOCK != 0 {
+		t.Errorf("OpenSerial left O_NONBLOCK set, flags: %#o", flags)
+	}
+	got, err := Attr(f)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if got != *applied {
+		t.Errorf("OpenSerial applied: %v got: %v", *applied, got)
+	}
+	if got.OutputSpeed() != 115200 || got.Cflag&(CSIZE|PARENB|CSTOPB|CRTSCTS) != CS8 || got.Cflag&(CREAD|CLOCAL) != CREAD|CLOCAL {
+		t.Errorf("OpenSerial got speed: %d Cflag: %#o want 115200 8N1 CREAD CLOCAL", got.OutputSpeed(), got.Cflag)
+	}
+	if got.Lflag&(ICANON|ECHO) != 0 || got.Iflag&(IXON|IXOFF) != 0 {
+		t.Errorf("OpenSerial got Lflag: %#o Iflag: %#o want raw with no flow control", got.Lflag, got.Iflag)
+	}
+	if _, _, err := OpenSerial(name, 9601); err == nil {
+		t.Errorf("OpenSerial(%q, 9601) got: <nil> want: not a supported speed", name)
+	}
+	if _, _, err := OpenSerial("/nonexistent/tty", 9600); err == nil {
+		t.Errorf("OpenSerial of missing device got: <nil> want: error")
+	}
+}
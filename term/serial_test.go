@@ -0,0 +1,73 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "testing"
+
+// TestSerialSettings tests setting parity, data and stop bits.
+func TestSerialSettings(t *testing.T) {
+	var tr Termios
+	tsts := []struct {
+		parity Parity
+		want   uint32
+	}{
+		{ParityEven, PARENB},
+		{ParityOdd, PARENB | PARODD},
+		{ParityNone, 0},
+		{Parity(7), 0},
+	}
+	for _, tst := range tsts {
+		tr.SetParity(tst.parity)
+		if got := tr.Cflag & (PARENB | PARODD); got != tst.want {
+			t.Errorf("SetParity(%v) got: %#o want: %#o", tst.parity, got, tst.want)
+		}
+	}
+	for n, want := range map[int]uint32{5: CS5, 6: CS6, 7: CS7, 8: CS8} {
+		if err := tr.SetDataBits(n); err != nil || tr.Cflag&CSIZE != want {
+			t.Errorf("SetDataBits(%d) got: %#o,%v want: %#o,<nil>", n, tr.Cflag&CSIZE, err, want)
+		}
+	}
+	if err := tr.SetDataBits(9); err == nil {
+		t.Errorf("SetDataBits(9) got: <nil> want: not a valid size")
+	}
+	if err := tr.SetStopBits(2); err != nil || tr.Cflag&CSTOPB == 0 {
+		t.Errorf("SetStopBits(2) got Cflag: %#o,%v want CSTOPB set", tr.Cflag, err)
+	}
+	if err := tr.SetStopBits(1); err != nil || tr.Cflag&CSTOPB != 0 {
+		t.Errorf("SetStopBits(1) got Cflag: %#o,%v want CSTOPB cleared", tr.Cflag, err)
+	}
+	if err := tr.SetStopBits(3); err == nil {
+		t.Errorf("SetStopBits(3) got: <nil> want: not valid")
+	}
+}
+
+// TestConfigure tests setting all the line settings at once.
+func TestConfigure(t *testing.T) {
+	var tr Termios
+	if err := tr.Configure(9600, 7, ParityEven, 2); err != nil {
+		t.Fatalf("Configure(9600, 7, ParityEven, 2) failed: %v", err)
+	}
+	if tr.OutputSpeed() != 9600 || tr.Cflag&CSIZE != CS7 || tr.Cflag&(PARENB|PARODD) != PARENB || tr.Cflag&CSTOPB == 0 {
+		t.Errorf("Configure(9600, 7, ParityEven, 2) got speed: %d Cflag: %#o", tr.OutputSpeed(), tr.Cflag)
+	}
+	want := tr
+	for _, tst := range []struct {
+		baud, data int
+		parity     Parity
+		stop       int
+	}{
+		{9601, 8, ParityNone, 1},
+		{115200, 4, ParityNone, 1},
+		{115200, 8, Parity(3), 1},
+		{115200, 8, ParityNone, 0},
+	} {
+		if err := tr.Configure(tst.baud, tst.data, tst.parity, tst.stop); err == nil {
+			t.Errorf("Configure(%d, %d, %v, %d) got: <nil> want: error", tst.baud, tst.data, tst.parity, tst.stop)
+		}
+		if tr != want {
+			t.Errorf("Configure(%d, %d, %v, %d) failing changed: %v want: %v", tst.baud, tst.data, tst.parity, tst.stop, tr, want)
+		}
+	}
+}
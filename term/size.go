@@ -0,0 +1,193 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+)
+
+// WinSize holds the size of a terminal window.
+type WinSize struct {
+	Rows   uint16 // Rows number of rows
+	Cols   uint16 // Cols number of columns
+	XPixel uint16 // XPixel width in pixels
+	YPixel uint16 // YPixel height in pixels
+}
+
+// winSize converts the Winsize ws to a WinSize.
+func winSize(ws Winsize) WinSize {
+	return WinSize{Rows: ws.WsRow, Cols: ws.WsCol, XPixel: ws.WsXpixel, YPixel: ws.WsYpixel}
+}
+
+// winsize converts ws to the Winsize used by the IOCTLs.
+func (ws WinSize) winsize() Winsize {
+	return Winsize{WsRow: ws.Rows, WsCol: ws.Cols, WsXpixel: ws.XPixel, WsYpixel: ws.YPixel}
+}
+
+// CellWidth returns the width of a character cell in pixels.
+// 0 is returned if the terminal doesn't report its pixel size or has no columns.
+func (ws WinSize) CellWidth() int {
+	if ws.Cols == 0 {
+		return 0
+	}
+	return int(ws.XPixel / ws.Cols)
+}
+
+// CellHeight returns the height of a character cell in pixels.
+// 0 is returned if the terminal doesn't report its pixel size or has no rows.
+func (ws WinSize) CellHeight() int {
+	if ws.Rows == 0 {
+		return 0
+	}
+	return int(ws.YPixel / ws.Rows)
+}
+
+// GetWinSize returns the window size of the terminal f, including the pixel size.
+func GetWinSize(f *os.File) (WinSize, error) {
+	ws, err := getWinsize(f)
+	if err != nil {
+		return WinSize{}, err
+	}
+	return winSize(ws), nil
+}
+
+// SetWinSize sets the window size of the terminal f, including the pixel size.
+func SetWinSize(f *os.File, ws WinSize) error {
+	kws := ws.winsize()
+	return setWinsize(f, &kws)
+}
+
+// WinSize returns the window size in Wz as a WinSize.
+// Wz uses the Winsize struct matching the layout of the IOCTLs, kept as is for compatibility,
+// GetWinSize and SetWinSize are what to use for the window size now.
+func (t *Termios) WinSize() WinSize {
+	return winSize(t.Wz)
+}
+
+// Winsz Fetches the current terminal windowsize.
+// example handling changing window sizes with PTYs:
+//
+// import "os"
+// import "os/signal"
+//
+// var sig = make(chan os.Signal,2) 		// Channel to listen for UNIX SIGNALS on
+// signal.Notify(sig, syscall.SIGWINCH) // That'd be the window changing
+//
+//	for {
+//		<-sig
+//		term.Winsz(os.Stdin)			// We got signaled our terminal changed size so we read in the new value
+//	 term.Setwinsz(pty.Slave) // Copy it to our virtual Terminal
+//	}
+//
+// Deprecated: Use GetWinSize, it returns the size as a WinSize, or CopySize for the example above.
+func (t *Termios) Winsz(file *os.File) error {
+	ws, err := GetWinSize(file)
+	if err != nil {
+		return err
+	}
+	t.Wz = ws.winsize()
+	return nil
+}
+
+// Setwinsz Sets the terminal window size.
+//
+// Deprecated: Use SetWinSize.
+func (t *Termios) Setwinsz(file *os.File) error {
+	return SetWinSize(file, t.WinSize())
+}
+
+// GetSize returns the number of rows and columns of the terminal f.
+func GetSize(f *os.File) (rows, cols int, err error) {
+	ws, err := getWinsize(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.WsRow), int(ws.WsCol), nil
+}
+
+// SizeSource is where GetSizeOrEnv got the size from.
+type SizeSource int
+
+// Size sources, in the order GetSizeOrEnv tries them.
+const (
+	SizeTerminal SizeSource = iota // SizeTerminal the terminal itself
+	SizeEnv                        // SizeEnv the LINES and COLUMNS environment variables
+	SizeDefault                    // SizeDefault nothing known, the default 24x80
+)
+
+// String implements the Stringer interface.
+func (s SizeSource) String() string {
+	switch s {
+	case SizeTerminal:
+		return "terminal"
+	case SizeEnv:
+		return "env"
+	case SizeDefault:
+		return "default"
+	}
+	return "SizeSource(" + strconv.Itoa(int(s)) + ")"
+}
+
+// The size used by GetSizeOrEnv when nothing else is known.
+const (
+	defaultRows = 24
+	defaultCols = 80
+)
+
+// GetSizeOrEnv returns the number of rows and columns of the terminal f for output that might
+// not go to a terminal, eg. a pager. If f isn't a terminal, or reports a size of 0, the LINES and
+// COLUMNS environment variables are used and failing those the default 24x80. Where the size came
+// from is returned in src. A variable that isn't set to a positive number is skipped, with only one
+// of them usable the other dimension gets the default and src is SizeEnv.
+// There's always a size to use so no error is returned.
+func GetSizeOrEnv(f *os.File) (rows, cols int, src SizeSource) {
+	if rows, cols, err := GetSize(f); err == nil && rows > 0 && cols > 0 {
+		return rows, cols, SizeTerminal
+	}
+	rows, cols, src = defaultRows, defaultCols, SizeDefault
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		rows, src = n, SizeEnv
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		cols, src = n, SizeEnv
+	}
+	return rows, cols, src
+}
+
+// GetSizePixels returns the number of rows and columns of the terminal f as well as the width (xpix)
+// and height (ypix) in pixels. Terminals not reporting their pixel size gives 0 for xpix and ypix.
+func GetSizePixels(f *os.File) (rows, cols, xpix, ypix int, err error) {
+	ws, err := getWinsize(f)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return int(ws.WsRow), int(ws.WsCol), int(ws.WsXpixel), int(ws.WsYpixel), nil
+}
+
+// CopySize copies the window size of the terminal src to the terminal dst.
+// This is what's needed to keep a PTY the same size as the real terminal on SIGWINCH.
+func CopySize(dst, src *os.File) error {
+	ws, err := getWinsize(src)
+	if err != nil {
+		return err
+	}
+	return setWinsize(dst, &ws)
+}
+
+// SetSize sets the number of rows and columns of the terminal f.
+// The value of rows and cols have to be in the range 0-65535.
+func SetSize(f *os.File, rows, cols int) error {
+	if rows < 0 || rows > math.MaxUint16 {
+		return errors.New("rows: " + strconv.Itoa(rows) + " not a valid size 0-65535")
+	}
+	if cols < 0 || cols > math.MaxUint16 {
+		return errors.New("cols: " + strconv.Itoa(cols) + " not a valid size 0-65535")
+	}
+	ws := Winsize{WsRow: uint16(rows), WsCol: uint16(cols)}
+	return setWinsize(f, &ws)
+}
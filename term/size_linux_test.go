@@ -0,0 +1,346 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestGetSize tests reading the rows and columns of a terminal.
+func TestGetSize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	var tr Termios
+	tr.Wz.WsRow, tr.Wz.WsCol = 24, 80
+	if err := tr.Setwinsz(tty.Slave); err != nil {
+		t.Fatalf("Setwinsz failed: %v", err)
+	}
+	rows, cols, err := GetSize(tty.Slave)
+	if err != nil {
+		t.Fatalf("GetSize(tty.Slave) failed: %v", err)
+	}
+	if rows != 24 || cols != 80 {
+		t.Errorf("GetSize got rows: %d cols: %d want rows: 24 cols: 80", rows, cols)
+	}
+	nf, err := donormfile("TestGetSize")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestGetSize\") failed: %v", err)
+	}
+	defer nf.Close()
+	if _, _, err := GetSize(nf); err == nil {
+		t.Errorf("GetSize(%q) got: <nil> want: not a tty error", nf.Name())
+	}
+}
+
+// TestSetSize tests setting the rows and columns of a terminal.
+func TestSetSize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tsts := []struct {
+		rows, cols int
+		fail       bool
+	}{
+		{24, 80, false},
+		{0, 0, false},
+		{65535, 65535, false},
+		{65536, 80, true},
+		{24, 65536, true},
+		{-1, 80, true},
+		{24, -1, true},
+	}
+	for _, tst := range tsts {
+		err := SetSize(tty.Slave, tst.rows, tst.cols)
+		if tst.fail {
+			if err == nil {
+				t.Errorf("SetSize(tty.Slave, %d, %d) got: <nil> want: out of range error", tst.rows, tst.cols)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SetSize(tty.Slave, %d, %d) failed: %v", tst.rows, tst.cols, err)
+			continue
+		}
+		rows, cols, err := GetSize(tty.Slave)
+		if err != nil {
+			t.Fatalf("GetSize(tty.Slave) failed: %v", err)
+		}
+		if rows != tst.rows || cols != tst.cols {
+			t.Errorf("SetSize got rows: %d cols: %d want rows: %d cols: %d", rows, cols, tst.rows, tst.cols)
+		}
+	}
+}
+
+// TestGetSizeOrEnv tests falling back to the environment and the default size.
+func TestGetSizeOrEnv(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	nf, err := donormfile("TestGetSizeOrEnv")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestGetSizeOrEnv\") failed: %v", err)
+	}
+	defer nf.Close()
+	tsts := []struct {
+		f              *os.File
+		size           [2]int
+		lines, columns string
+		rows, cols     int
+		src            SizeSource
+	}{
+		{tty.Slave, [2]int{30, 100}, "50", "150", 30, 100, SizeTerminal},
+		{tty.Slave, [2]int{0, 0}, "50", "150", 50, 150, SizeEnv},
+		{nf, [2]int{}, "50", "150", 50, 150, SizeEnv},
+		{nf, [2]int{}, "", "150", 24, 150, SizeEnv},
+		{nf, [2]int{}, "-1", "wide", 24, 80, SizeDefault},
+		{nf, [2]int{}, "", "", 24, 80, SizeDefault},
+	}
+	for _, tst := range tsts {
+		if tst.f == tty.Slave {
+			if err := SetSize(tty.Slave, tst.size[0], tst.size[1]); err != nil {
+				t.Fatalf("SetSize failed: %v", err)
+			}
+		}
+		t.Setenv("LINES", tst.lines)
+		t.Setenv("COLUMNS", tst.columns)
+		rows, cols, src := GetSizeOrEnv(tst.f)
+		if rows != tst.rows || cols != tst.cols || src != tst.src {
+			t.Errorf("GetSizeOrEnv(%s) LINES=%q COLUMNS=%q got: %d,%d,%v want: %d,%d,%v", tst.f.Name(), tst.lines, tst.columns, rows, cols, src, tst.rows, tst.cols, tst.src)
+		}
+	}
+}
+
+// TestGetSizePixels tests reading the pixel size of a terminal.
+func TestGetSizePixels(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	var tr Termios
+	tr.Wz = Winsize{WsRow: 24, WsCol: 80, WsXpixel: 640, WsYpixel: 480}
+	if err := tr.Setwinsz(tty.Slave); err != nil {
+		t.Fatalf("Setwinsz failed: %v", err)
+	}
+	rows, cols, xpix, ypix, err := GetSizePixels(tty.Slave)
+	if err != nil {
+		t.Fatalf("GetSizePixels(tty.Slave) failed: %v", err)
+	}
+	if rows != 24 || cols != 80 || xpix != 640 || ypix != 480 {
+		t.Errorf("GetSizePixels got: %d %d %d %d want: 24 80 640 480", rows, cols, xpix, ypix)
+	}
+	var got Termios
+	if err := got.Winsz(tty.Slave); err != nil {
+		t.Fatalf("Winsz failed: %v", err)
+	}
+	if got.Wz != tr.Wz {
+		t.Errorf("Winsz got: %v want: %v", got.Wz, tr.Wz)
+	}
+}
+
+// TestWinSize tests getting and setting the size as a WinSize.
+func TestWinSize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	want := WinSize{Rows: 24, Cols: 80, XPixel: 640, YPixel: 480}
+	if err := SetWinSize(tty.Slave, want); err != nil {
+		t.Fatalf("SetWinSize failed: %v", err)
+	}
+	got, err := GetWinSize(tty.Slave)
+	if err != nil || got != want {
+		t.Errorf("GetWinSize got: %v,%v want: %v,<nil>", got, err, want)
+	}
+	if got.CellWidth() != 8 || got.CellHeight() != 20 {
+		t.Errorf("CellWidth, CellHeight got: %d,%d want: 8,20", got.CellWidth(), got.CellHeight())
+	}
+	var tr Termios
+	if err := tr.Winsz(tty.Slave); err != nil || tr.WinSize() != want {
+		t.Errorf("Termios.WinSize got: %v,%v want: %v,<nil>", tr.WinSize(), err, want)
+	}
+	for _, ws := range []WinSize{{}, {Rows: 24, Cols: 80}, {XPixel: 640, YPixel: 480}} {
+		if ws.CellWidth() != 0 || ws.CellHeight() != 0 {
+			t.Errorf("%v CellWidth, CellHeight got: %d,%d want: 0,0", ws, ws.CellWidth(), ws.CellHeight())
+		}
+	}
+}
+
+// TestNotifyResize tests getting the new terminal size on SIGWINCH.
+func TestNotifyResize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	sizes, stop := NotifyResize(tty.Slave)
+	for _, sz := range []WinSize{{Rows: 24, Cols: 80}, {Rows: 50, Cols: 132}} {
+		if err := SetSize(tty.Slave, int(sz.Rows), int(sz.Cols)); err != nil {
+			t.Fatalf("SetSize failed: %v", err)
+		}
+		if err := syscall.Kill(os.Getpid(), syscall.SIGWINCH); err != nil {
+			t.Fatalf("Kill(SIGWINCH) failed: %v", err)
+		}
+		select {
+		case got := <-sizes:
+			if got != sz {
+				t.Errorf("NotifyResize got: %v want: %v", got, sz)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("NotifyResize timed out waiting for SIGWINCH")
+		}
+	}
+	// A burst of resizes should only leave the last one.
+	for i := 0; i < 10; i++ {
+		SetSize(tty.Slave, 10+i, 10+i)
+		syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+	}
+	want := WinSize{Rows: 19, Cols: 19}
+	for got := (WinSize{}); got != want; {
+		select {
+		case got = <-sizes:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("NotifyResize got: %v want: %v", got, want)
+		}
+	}
+	stop()
+	stop()
+	// A signal from the burst still in flight may have left one more size.
+	if _, ok := <-sizes; ok {
+		if _, ok := <-sizes; ok {
+			t.Error("NotifyResize channel should be closed after stop")
+		}
+	}
+}
+
+// TestGetSizeChanges tests a burst of resizes coming out as one change.
+func TestGetSizeChanges(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := SetSize(tty.Slave, 24, 80); err != nil {
+		t.Fatalf("SetSize failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sizes := GetSizeChanges(ctx, tty.Slave, 100*time.Millisecond)
+	// Give the goroutine time to read the starting size.
+	time.Sleep(50 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		SetSize(tty.Slave, 10+i, 10+i)
+		syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+	}
+	select {
+	case got := <-sizes:
+		if want := (WinSize{Rows: 19, Cols: 19}); got != want {
+			t.Errorf("GetSizeChanges got: %v want: %v", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetSizeChanges timed out waiting for SIGWINCH")
+	}
+	// No change no size.
+	syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+	select {
+	case got := <-sizes:
+		t.Errorf("GetSizeChanges for the same size got: %v want: nothing", got)
+	case <-time.After(300 * time.Millisecond):
+	}
+	cancel()
+	select {
+	case _, ok := <-sizes:
+		if ok {
+			t.Error("GetSizeChanges channel should be closed after cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetSizeChanges channel not closed after cancel")
+	}
+}
+
+// TestWaitResize tests blocking for the next SIGWINCH.
+func TestWaitResize(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	want := WinSize{Rows: 40, Cols: 120}
+	if err := SetSize(tty.Slave, int(want.Rows), int(want.Cols)); err != nil {
+		t.Fatalf("SetSize failed: %v", err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Keep signalling, one sent before WaitResize installed its handler is lost.
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+				syscall.Kill(os.Getpid(), syscall.SIGWINCH)
+			}
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if got, err := WaitResize(ctx, tty.Slave); err != nil || got != want {
+		t.Errorf("WaitResize got: %v,%v want: %v,<nil>", got, err, want)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := WaitResize(ctx, tty.Slave); err != context.Canceled {
+		t.Errorf("WaitResize with cancelled context got: %v want: %v", err, context.Canceled)
+	}
+}
+
+// TestCopySize tests copying the window size between terminals.
+func TestCopySize(t *testing.T) {
+	src, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer src.Close()
+	dst, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer dst.Close()
+	var tr Termios
+	tr.Wz = Winsize{WsRow: 42, WsCol: 142, WsXpixel: 1420, WsYpixel: 840}
+	if err := tr.Setwinsz(src.Slave); err != nil {
+		t.Fatalf("Setwinsz failed: %v", err)
+	}
+	if err := CopySize(dst.Slave, src.Slave); err != nil {
+		t.Fatalf("CopySize failed: %v", err)
+	}
+	var got Termios
+	if err := got.Winsz(dst.Slave); err != nil {
+		t.Fatalf("Winsz failed: %v", err)
+	}
+	if got.Wz != tr.Wz {
+		t.Errorf("CopySize got: %v want: %v", got.Wz, tr.Wz)
+	}
+	nf, err := donormfile("TestCopySize")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestCopySize\") failed: %v", err)
+	}
+	defer nf.Close()
+	if err := CopySize(dst.Slave, nf); err == nil {
+		t.Error("CopySize from a regular file got: <nil> want: not a tty error")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux
+
+package term
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// NotifyResize delivers the new size of the terminal f every time the process gets a SIGWINCH.
+// If the receiver falls behind only the latest size is kept, a burst of resizes won't fill up the channel.
+// Calling the returned func stops the notifications and closes the channel.
+//
+//	sizes, stop := term.NotifyResize(os.Stdin)
+//	defer stop()
+//	for ws := range sizes {
+//		term.SetSize(pty.Slave, int(ws.Rows), int(ws.Cols))
+//	}
+func NotifyResize(f *os.File) (<-chan WinSize, func()) {
+	ch := make(chan WinSize, 1)
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	signal.Notify(sig, syscall.SIGWINCH)
+	go func() {
+		defer close(finished)
+		defer close(ch)
+		for {
+			select {
+			case <-done:
+				return
+			case <-sig:
+			}
+			ws, err := getWinsize(f)
+			if err != nil {
+				continue
+			}
+			sendLatest(ch, winSize(ws))
+		}
+	}()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// WaitResize blocks until the process gets a SIGWINCH and returns the new size of the terminal f.
+// A SIGWINCH handler is installed for the call only. If ctx is done first ctx.Err() is returned.
+// For a stream of sizes use NotifyResize, WaitResize misses the resizes happening between calls.
+func WaitResize(ctx context.Context, f *os.File) (WinSize, error) {
+	if err := ctx.Err(); err != nil {
+		return WinSize{}, err
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	defer signal.Stop(sig)
+	select {
+	case <-ctx.Done():
+		return WinSize{}, ctx.Err()
+	case <-sig:
+	}
+	ws, err := getWinsize(f)
+	if err != nil {
+		return WinSize{}, err
+	}
+	return winSize(ws), nil
+}
+
+// GetSizeChanges delivers the size of the terminal f when it changes until ctx is done, then the
+// channel is closed. The SIGWINCHs coming within debounce of each other are taken as one, the size
+// is read once debounce passed without another. A size is only sent if it differs from the last one
+// sent, the first from the size when GetSizeChanges was called. Like NotifyResize only the latest
+// size is kept.
+//
+//	for ws := range term.GetSizeChanges(ctx, os.Stdin, 50*time.Millisecond) {
+//		redraw(ws)
+//	}
+func GetSizeChanges(ctx context.Context, f *os.File, debounce time.Duration) <-chan WinSize {
+	ch := make(chan WinSize, 1)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	go func() {
+		defer close(ch)
+		defer signal.Stop(sig)
+		var last WinSize
+		if ws, err := getWinsize(f); err == nil {
+			last = winSize(ws)
+		}
+		settled := time.NewTimer(debounce)
+		settled.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sig:
+				settled.Reset(debounce)
+				continue
+			case <-settled.C:
+			}
+			ws, err := getWinsize(f)
+			if err != nil || winSize(ws) == last {
+				continue
+			}
+			last = winSize(ws)
+			sendLatest(ch, last)
+		}
+	}()
+	return ch
+}
+
+// sendLatest sends ws on ch throwing away the size not picked up yet, ch must have a buffer of 1.
+func sendLatest(ch chan WinSize, ws WinSize) {
+	select {
+	case ch <- ws:
+	default:
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- ws
+	}
+}
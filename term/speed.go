@@ -0,0 +1,62 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"errors"
+	"strconv"
+)
+
+// InputSpeed returns the input baud rate of terminal t, -1 if the speed is unknown.
+func (t *Termios) InputSpeed() int {
+	return speedBaud(t.Ispeed)
+}
+
+// OutputSpeed returns the output baud rate of terminal t, -1 if the speed is unknown.
+func (t *Termios) OutputSpeed() int {
+	return speedBaud(t.Ospeed)
+}
+
+// SetSpeed sets both the input and output baud rate of terminal t eg. 9600 or 115200.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetSpeed(baud int) error {
+	code, err := baudCode(baud)
+	if err != nil {
+		return err
+	}
+	t.setSpeed(code, code)
+	return nil
+}
+
+// SetInputSpeed sets only the input baud rate of terminal t, the output rate is kept.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetInputSpeed(baud int) error {
+	code, err := baudCode(baud)
+	if err != nil {
+		return err
+	}
+	t.setSpeed(code, t.Ospeed)
+	return nil
+}
+
+// SetOutputSpeed sets only the output baud rate of terminal t, the input rate is kept.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetOutputSpeed(baud int) error {
+	code, err := baudCode(baud)
+	if err != nil {
+		return err
+	}
+	t.setSpeed(t.Ispeed, code)
+	return nil
+}
+
+// baudCode returns the speed code for baud or an error if it's not a supported speed.
+func baudCode(baud int) (uint32, error) {
+	code, ok := speedCode(baud)
+	if !ok {
+		return 0, errors.New("baud: " + strconv.Itoa(baud) + " not a supported speed")
+	}
+	return code, nil
+}
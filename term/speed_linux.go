@@ -0,0 +1,71 @@
+//go:build linux
+
+package term
+
+// The input speed is kept in Cflag shifted by IBSHIFT, 0 gives the same speed as the output.
+const (
+	CIBAUD  = 002003600000 // CIBAUD Input serial speed settings
+	IBSHIFT = 16           // IBSHIFT Shift of the input speed in CIBAUD
+)
+
+// speeds maps the baud rates to the Linux Bxxx speed codes.
+var speeds = map[int]uint32{
+	0:       0000000,
+	50:      0000001,
+	75:      0000002,
+	110:     0000003,
+	134:     0000004,
+	150:     0000005,
+	200:     0000006,
+	300:     0000007,
+	600:     0000010,
+	1200:    0000011,
+	1800:    0000012,
+	2400:    0000013,
+	4800:    0000014,
+	9600:    0000015,
+	19200:   0000016,
+	38400:   0000017,
+	57600:   0010001,
+	115200:  0010002,
+	230400:  0010003,
+	460800:  0010004,
+	500000:  0010005,
+	576000:  0010006,
+	921600:  0010007,
+	1000000: 0010010,
+	1152000: 0010011,
+	1500000: 0010012,
+	2000000: 0010013,
+	2500000: 0010014,
+	3000000: 0010015,
+	3500000: 0010016,
+	4000000: 0010017,
+}
+
+// speedCode returns the speed code used in Termios for baud.
+func speedCode(baud int) (uint32, bool) {
+	code, ok := speeds[baud]
+	return code, ok
+}
+
+// speedBaud returns the baud rate of the Termios speed code.
+func speedBaud(code uint32) int {
+	for baud, c := range speeds {
+		if c == code {
+			return baud
+		}
+	}
+	return -1
+}
+
+// setSpeed sets the speed codes of t.
+// Linux keeps the speed in Cflag, Ispeed and Ospeed are only there for reading it back.
+// The input speed only goes in CIBAUD if it differs from the output speed.
+func (t *Termios) setSpeed(ispeed, ospeed uint32) {
+	t.Cflag = t.Cflag&^(CBAUD|CBAUDEX|CIBAUD) | ospeed
+	if ispeed != ospeed {
+		t.Cflag |= ispeed << IBSHIFT
+	}
+	t.Ispeed, t.Ospeed = ispeed, ospeed
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import "testing"
+
+// TestSetSpeed tests setting and reading back the terminal speed.
+func TestSetSpeed(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	for _, baud := range []int{9600, 38400, 57600, 115200, 4000000} {
+		if err := tr.SetSpeed(baud); err != nil {
+			t.Errorf("SetSpeed(%d) failed: %v", baud, err)
+			continue
+		}
+		if tr.Cflag&(CBAUD|CBAUDEX) != speeds[baud] {
+			t.Errorf("SetSpeed(%d) got Cflag speed: %o want: %o", baud, tr.Cflag&(CBAUD|CBAUDEX), speeds[baud])
+		}
+		if err := tr.Set(tty.Slave); err != nil {
+			t.Fatalf("Set(tty.Slave) failed: %v", err)
+		}
+		got, err := Attr(tty.Slave)
+		if err != nil {
+			t.Fatalf("Attr(tty.Slave) failed: %v", err)
+		}
+		if got.InputSpeed() != baud || got.OutputSpeed() != baud {
+			t.Errorf("SetSpeed(%d) got InputSpeed: %d OutputSpeed: %d", baud, got.InputSpeed(), got.OutputSpeed())
+		}
+	}
+	for _, baud := range []int{-1, 9601, 4000001} {
+		if err := tr.SetSpeed(baud); err == nil {
+			t.Errorf("SetSpeed(%d) got: <nil> want: not a supported speed", baud)
+		}
+	}
+}
+
+// TestSetInputOutputSpeed tests setting different input and output speeds.
+func TestSetInputOutputSpeed(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	tsts := []struct {
+		set            func(baud int) error
+		baud           int
+		ispeed, ospeed int
+	}{
+		{tr.SetSpeed, 38400, 38400, 38400},
+		{tr.SetInputSpeed, 9600, 9600, 38400},
+		{tr.SetOutputSpeed, 115200, 9600, 115200},
+		{tr.SetInputSpeed, 115200, 115200, 115200},
+		{tr.SetOutputSpeed, 57600, 115200, 57600},
+	}
+	for _, tst := range tsts {
+		if err := tst.set(tst.baud); err != nil {
+			t.Fatalf("setting speed %d failed: %v", tst.baud, err)
+		}
+		if err := tr.Set(tty.Slave); err != nil {
+			t.Fatalf("Set(tty.Slave) failed: %v", err)
+		}
+		got, err := Attr(tty.Slave)
+		if err != nil {
+			t.Fatalf("Attr(tty.Slave) failed: %v", err)
+		}
+		if got.InputSpeed() != tst.ispeed || got.OutputSpeed() != tst.ospeed {
+			t.Errorf("setting speed %d got InputSpeed: %d OutputSpeed: %d want: %d %d", tst.baud, got.InputSpeed(), got.OutputSpeed(), tst.ispeed, tst.ospeed)
+		}
+	}
+	if err := tr.SetInputSpeed(9601); err == nil {
+		t.Errorf("SetInputSpeed(9601) got: <nil> want: not a supported speed")
+	}
+	if err := tr.SetOutputSpeed(-1); err == nil {
+		t.Errorf("SetOutputSpeed(-1) got: <nil> want: not a supported speed")
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package term
+
+// speeds are the baud rates supported, the speed is stored as is in Termios.
+var speeds = []int{
+	0, 50, 75, 110, 134, 150, 200, 300, 600, 1200, 1800, 2400, 4800, 9600,
+	19200, 38400, 57600, 115200, 230400, 460800, 921600,
+}
+
+// speedCode returns the speed code used in Termios for baud.
+func speedCode(baud int) (uint32, bool) {
+	for _, b := range speeds {
+		if b == baud {
+			return uint32(baud), true
+		}
+	}
+	return 0, false
+}
+
+// speedBaud returns the baud rate of the Termios speed code.
+func speedBaud(code uint32) int {
+	return int(code)
+}
+
+// setSpeed sets the speed codes of t.
+func (t *Termios) setSpeed(ispeed, ospeed uint32) {
+	t.Ispeed, t.Ospeed = ispeed, ospeed
+}
@@ -74,10 +74,22 @@ const (
 	sshTTYOPOSPEED = 129
 )
 
-var convertSSH = map[uint8]struct {
+// sshAttr is the Termios type and bit or Cc index an SSH terminal attribute maps to.
+type sshAttr struct {
 	tType  uint
 	native uint32
-}{
+}
+
+// sshCc maps an SSH control character to index i of Termios.Cc, it's left out if i is -1 as for
+// the characters the platform doesn't have.
+func sshCc(i int) sshAttr {
+	if i < 0 {
+		return sshAttr{tType: sshNOP}
+	}
+	return sshAttr{tType: sshCchar, native: uint32(i)}
+}
+
+var convertSSH = map[uint8]sshAttr{
 	sshTTYOPEND:    {tType: sshNOP},
 	sshVINTR:       {tType: sshCchar, native: VINTR},
 	sshVQUIT:       {tType: sshCchar, native: VQUIT},
@@ -94,7 +106,7 @@ var convertSSH = map[uint8]struct {
 	sshVWERASE:     {tType: sshCchar, native: VWERASE},
 	sshVLNEXT:      {tType: sshCchar, native: VLNEXT},
 	sshVFLUSH:      {tType: sshNOP},
-	sshVSWTCH:      {tType: sshCchar, native: VSWTC},
+	sshVSWTCH:      sshCc(VSWTC),
 	sshVSTATUS:     {tType: sshNOP},
 	sshVDISCARD:    {tType: sshCchar, native: VDISCARD},
 	sshIGNPAR:      {tType: sshIflag, native: IGNPAR},
@@ -0,0 +1,159 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"strconv"
+	"strings"
+)
+
+// flagName is the stty name of a flag bit.
+type flagName struct {
+	name string
+	bit  uint32
+}
+
+// The flags shown by String and Diff, in the order stty -a shows them.
+var (
+	cflagNames = []flagName{
+		{"parenb", PARENB}, {"parodd", PARODD}, {"hupcl", HUPCL}, {"cstopb", CSTOPB},
+		{"cread", CREAD}, {"clocal", CLOCAL}, {"crtscts", CRTSCTS},
+	}
+	iflagNames = []flagName{
+		{"ignbrk", IGNBRK}, {"brkint", BRKINT}, {"ignpar", IGNPAR}, {"parmrk", PARMRK},
+		{"inpck", INPCK}, {"istrip", ISTRIP}, {"inlcr", INLCR}, {"igncr", IGNCR},
+		{"icrnl", ICRNL}, {"ixon", IXON}, {"ixoff", IXOFF}, {"iuclc", IUCLC},
+		{"ixany", IXANY}, {"imaxbel", IMAXBEL}, {"iutf8", IUTF8},
+	}
+	oflagNames = []flagName{
+		{"opost", OPOST}, {"olcuc", OLCUC}, {"ocrnl", OCRNL}, {"onlcr", ONLCR},
+		{"onocr", ONOCR}, {"onlret", ONLRET}, {"ofill", OFILL}, {"ofdel", OFDEL},
+	}
+	lflagNames = []flagName{
+		{"isig", ISIG}, {"icanon", ICANON}, {"iexten", IEXTEN}, {"echo", ECHO},
+		{"echoe", ECHOE}, {"echok", ECHOK}, {"echonl", ECHONL}, {"noflsh", NOFLSH},
+		{"xcase", XCASE}, {"tostop", TOSTOP}, {"echoprt", ECHOPRT}, {"echoctl", ECHOCTL},
+		{"echoke", ECHOKE},
+	}
+)
+
+// csizeNames are the character sizes of CSIZE.
+var csizeNames = map[uint32]string{CS5: "cs5", CS6: "cs6", CS7: "cs7", CS8: "cs8"}
+
+// ccNames are the stty names of the control characters.
+// Characters the platform doesn't have are -1 and left out.
+var ccNames = []struct {
+	name  string
+	index int
+}{
+	{"intr", VINTR}, {"quit", VQUIT}, {"erase", VERASE}, {"kill", VKILL}, {"eof", VEOF},
+	{"eol", VEOL}, {"eol2", VEOL2}, {"swtch", VSWTC}, {"start", VSTART}, {"stop", VSTOP},
+	{"susp", VSUSP}, {"rprnt", VREPRINT}, {"werase", VWERASE}, {"lnext", VLNEXT},
+	{"discard", VDISCARD}, {"min", VMIN}, {"time", VTIME},
+}
+
+// flagWords returns the names of flags as stty shows them, prefixed with - if the bit isn't set in v.
+// Flags the platform doesn't have are 0 and left out.
+func flagWords(flags []flagName, v uint32) []string {
+	words := make([]string, 0, len(flags))
+	for _, f := range flags {
+		if f.bit == 0 {
+			continue
+		}
+		if v&f.bit != 0 {
+			words = append(words, f.name)
+		} else {
+			words = append(words, "-"+f.name)
+		}
+	}
+	return words
+}
+
+// ccString formats the control character c at index of Cc the way stty does.
+func ccString(index int, c byte) string {
+	switch {
+	case index == VMIN || index == VTIME:
+		return strconv.Itoa(int(c))
+	case c == 0:
+		return "<undef>"
+	case c < 0x20:
+		return "^" + string(rune(c+0x40))
+	case c == 0x7f:
+		return "^?"
+	}
+	return string(rune(c))
+}
+
+// String dumps the terminal attributes in t like "stty -a" does.
+// The speed, line and control characters go on the first lines followed by one line each for
+// the control, input, output and local flags. A flag prefixed with - is off.
+func (t Termios) String() string {
+	var b strings.Builder
+	b.WriteString("speed " + strconv.Itoa(t.OutputSpeed()) + " baud; line = " + strconv.Itoa(int(t.Line)) + ";\n")
+	for i, cc := range ccNames {
+		if cc.index < 0 {
+			continue
+		}
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(cc.name + " = " + ccString(cc.index, t.Cc[cc.index]) + ";")
+	}
+	b.WriteString("\n")
+	cflags := flagWords(cflagNames, t.Cflag)
+	if cs, ok := csizeNames[t.Cflag&CSIZE]; ok {
+		cflags = append(cflags, cs)
+	}
+	for _, words := range [][]string{cflags, flagWords(iflagNames, t.Iflag), flagWords(oflagNames, t.Oflag), flagWords(lflagNames, t.Lflag)} {
+		b.WriteString(strings.Join(words, " ") + "\n")
+	}
+	return b.String()
+}
+
+// Diff lists what differs in other compared to t, in stty notation so it reads as the settings
+// taking t to other, eg. [-icanon -echo min = 1] for what MakeRaw changed.
+// The speeds, line, character size, flags and control characters are compared, in that order.
+func (t Termios) Diff(other Termios) []string {
+	var diff []string
+	if was, now := t.InputSpeed(), other.InputSpeed(); was != now {
+		diff = append(diff, "ispeed "+strconv.Itoa(now))
+	}
+	if was, now := t.OutputSpeed(), other.OutputSpeed(); was != now {
+		diff = append(diff, "ospeed "+strconv.Itoa(now))
+	}
+	if t.Line != other.Line {
+		diff = append(diff, "line = "+strconv.Itoa(int(other.Line)))
+	}
+	if t.Cflag&CSIZE != other.Cflag&CSIZE {
+		diff = append(diff, csizeNames[other.Cflag&CSIZE])
+	}
+	for _, fl := range []struct {
+		names     []flagName
+		was, next uint32
+	}{
+		{cflagNames, t.Cflag, other.Cflag},
+		{iflagNames, t.Iflag, other.Iflag},
+		{oflagNames, t.Oflag, other.Oflag},
+		{lflagNames, t.Lflag, other.Lflag},
+	} {
+		for _, f := range fl.names {
+			switch {
+			case fl.was&f.bit == 0 && fl.next&f.bit != 0:
+				diff = append(diff, f.name)
+			case fl.was&f.bit != 0 && fl.next&f.bit == 0:
+				diff = append(diff, "-"+f.name)
+			}
+		}
+	}
+	for _, cc := range ccNames {
+		if cc.index < 0 {
+			continue
+		}
+		if t.Cc[cc.index] != other.Cc[cc.index] {
+			diff = append(diff, cc.name+" = "+ccString(cc.index, other.Cc[cc.index]))
+		}
+	}
+	return diff
+}
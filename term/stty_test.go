@@ -0,0 +1,46 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTermiosString tests dumping the attributes like stty -a.
+func TestTermiosString(t *testing.T) {
+	tr := NewMockTTY("").Termios
+	tr.Cc[VINTR] = 0x03
+	tr.Cc[VERASE] = 0x7f
+	tr.Cc[VEOL] = 0
+	s := tr.String()
+	for _, want := range []string{"intr = ^C;", "erase = ^?;", "eol = <undef>;", "min = 1;", "cs8", " icanon ", " echo ", "-ignbrk", "opost"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() got: %q want it to contain: %q", s, want)
+		}
+	}
+	if lines := strings.Count(s, "\n"); lines != 6 {
+		t.Errorf("String() got %d lines want: 6", lines)
+	}
+}
+
+// TestTermiosDiff tests listing the changed attributes.
+func TestTermiosDiff(t *testing.T) {
+	cooked := NewMockTTY("").Termios
+	if diff := cooked.Diff(cooked); len(diff) != 0 {
+		t.Errorf("Diff of same attributes got: %q want: []", diff)
+	}
+	next := cooked
+	next.Lflag &^= ICANON | ECHO
+	next.Iflag |= IGNCR
+	next.Cflag = next.Cflag&^CSIZE | CS7
+	next.Cc[VMIN] = 0
+	next.Cc[VINTR] = 0x01
+	want := []string{"cs7", "igncr", "-icanon", "-echo", "intr = ^A", "min = 0"}
+	if diff := cooked.Diff(next); !reflect.DeepEqual(diff, want) {
+		t.Errorf("Diff got: %q want: %q", diff, want)
+	}
+}
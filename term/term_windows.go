@@ -0,0 +1,207 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+
+package term
+
+import (
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// CRTSCTS RTS/CTS hardware flow control, only here to keep the Termios methods the same on all platforms.
+const CRTSCTS = 020000000000
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// coord is the Windows COORD struct.
+type coord struct {
+	x int16
+	y int16
+}
+
+// smallRect is the Windows SMALL_RECT struct.
+type smallRect struct {
+	left   int16
+	top    int16
+	right  int16
+	bottom int16
+}
+
+// consoleScreenBufferInfo is the Windows CONSOLE_SCREEN_BUFFER_INFO struct.
+type consoleScreenBufferInfo struct {
+	size              coord
+	cursorPosition    coord
+	attributes        uint16
+	window            smallRect
+	maximumWindowSize coord
+}
+
+// Isatty returns true if file is a console.
+func Isatty(file *os.File) bool {
+	return IsattyFd(file.Fd())
+}
+
+// IsattyFd returns true if the handle fd is a console.
+func IsattyFd(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// IsTerminal returns true if f is a console.
+// Windows doesn't tell a failing check apart from not being a console so the error is always nil.
+func IsTerminal(f *os.File) (bool, error) {
+	return Isatty(f), nil
+}
+
+// TerminalName is not supported on Windows, an empty string is returned.
+func TerminalName(f *os.File) string {
+	return ""
+}
+
+// CtermID is not supported on Windows, ErrUnsupported is returned.
+func CtermID() (string, error) {
+	return "", ErrUnsupported
+}
+
+// OpenControllingTerminal is not supported on Windows, ErrUnsupported is returned.
+func OpenControllingTerminal() (*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+// Detach is not supported on Windows, ErrUnsupported is returned.
+func Detach(f *os.File) error {
+	return ErrUnsupported
+}
+
+// SetControllingTTY is not supported on Windows, ErrUnsupported is returned.
+func SetControllingTTY(f *os.File) error {
+	return ErrUnsupported
+}
+
+// Tcgetpgrp is not supported on Windows, ErrUnsupported is returned.
+func Tcgetpgrp(f *os.File) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// Tcsetpgrp is not supported on Windows, ErrUnsupported is returned.
+func Tcsetpgrp(f *os.File, pgid int) error {
+	return ErrUnsupported
+}
+
+// getWinsize reads the console window size of file.
+// The pixel sizes are not available on Windows and set to 0.
+func getWinsize(file *os.File) (Winsize, error) {
+	var ws Winsize
+	var info consoleScreenBufferInfo
+	r, _, err := procGetConsoleScreenBufferInfo.Call(file.Fd(), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return ws, err
+	}
+	ws.WsRow = uint16(info.window.bottom - info.window.top + 1)
+	ws.WsCol = uint16(info.window.right - info.window.left + 1)
+	return ws, nil
+}
+
+// setBlocking is not supported on Windows.
+func setBlocking(f *os.File) error {
+	return ErrUnsupported
+}
+
+// setWinsize is not supported on Windows.
+func setWinsize(file *os.File, ws *Winsize) error {
+	return ErrUnsupported
+}
+
+// Set is not supported on Windows.
+func (t *Termios) Set(file *os.File) error {
+	return ErrUnsupported
+}
+
+// SetDrain is not supported on Windows.
+func (t *Termios) SetDrain(file *os.File) error {
+	return ErrUnsupported
+}
+
+// SetFlush is not supported on Windows.
+func (t *Termios) SetFlush(file *os.File) error {
+	return ErrUnsupported
+}
+
+// Attr is not supported on Windows.
+func Attr(file *os.File) (Termios, error) {
+	return Termios{}, ErrUnsupported
+}
+
+// OpenPTY is not supported on Windows.
+func OpenPTY() (*PTY, error) {
+	return nil, ErrUnsupported
+}
+
+// OpenPTYMaster is not supported on Windows.
+func OpenPTYMaster() (*PTY, error) {
+	return nil, ErrUnsupported
+}
+
+// PTSName is not supported on Windows.
+func (p *PTY) PTSName() (string, error) {
+	return "", ErrUnsupported
+}
+
+// PTSNumber is not supported on Windows.
+func (p *PTY) PTSNumber() (uint, error) {
+	return 0, ErrUnsupported
+}
+
+// tcflush is not supported on Windows.
+func tcflush(f *os.File, which int) error {
+	return ErrUnsupported
+}
+
+// tcdrain is not supported on Windows.
+func tcdrain(f *os.File) error {
+	return ErrUnsupported
+}
+
+// setBreak is not supported on Windows.
+func setBreak(f *os.File, on bool) error {
+	return ErrUnsupported
+}
+
+// Available is not supported on Windows.
+func Available(f *os.File) (int, error) {
+	return 0, ErrUnsupported
+}
+
+// ModemStatus is not supported on Windows.
+func ModemStatus(f *os.File) (ModemBits, error) {
+	return 0, ErrUnsupported
+}
+
+// SetModemBits is not supported on Windows.
+func SetModemBits(f *os.File, bits ModemBits) error {
+	return ErrUnsupported
+}
+
+// ClearModemBits is not supported on Windows.
+func ClearModemBits(f *os.File, bits ModemBits) error {
+	return ErrUnsupported
+}
+
+// WaitReadable is not supported on Windows.
+func WaitReadable(files []*os.File, timeout time.Duration) ([]*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+// IsConnected on Windows only reports a closed f as not connected.
+func IsConnected(f *os.File) bool {
+	_, err := f.Stat()
+	return err == nil
+}
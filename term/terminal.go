@@ -0,0 +1,110 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Terminal is what the password and mode helpers need from a terminal.
+// NewTerminal gives one backed by a real tty, MockTTY an in-memory one for tests.
+type Terminal interface {
+	io.ReadWriter
+	Attr() (Termios, error)            // Attr gets the terminal attributes
+	SetAttr(t *Termios) error          // SetAttr sets the terminal attributes
+	Isatty() bool                      // Isatty returns true if this is a tty
+	SetReadDeadline(t time.Time) error // SetReadDeadline same as for os.File, can be a no-op
+}
+
+// fileTerminal is a Terminal doing the IOCTLs on a file.
+type fileTerminal struct {
+	*os.File
+}
+
+// NewTerminal returns a Terminal for the tty f.
+func NewTerminal(f *os.File) Terminal {
+	return fileTerminal{f}
+}
+
+// Attr gets the attributes of the terminal.
+func (ft fileTerminal) Attr() (Termios, error) {
+	return Attr(ft.File)
+}
+
+// SetAttr sets the attributes of the terminal.
+func (ft fileTerminal) SetAttr(t *Termios) error {
+	return t.Set(ft.File)
+}
+
+// Isatty returns true if the file is a tty.
+func (ft fileTerminal) Isatty() bool {
+	return Isatty(ft.File)
+}
+
+// MockTTY is an in-memory Terminal for testing code using the package without a real terminal.
+// Reads come from In, with nothing left they give io.EOF, and writes go to Out.
+// The attributes are kept in Termios and every change is appended to History so tests can check
+// the raw and cooked transitions. There's no line discipline, input is never echoed or edited.
+// MockTTY is not safe for concurrent use.
+type MockTTY struct {
+	In      bytes.Buffer // In input for reads, as typed by the user
+	Out     bytes.Buffer // Out everything written to the terminal
+	Termios Termios      // Termios the current attributes
+	History []Termios    // History the attributes set, oldest first
+	NotTTY  bool         // NotTTY makes the mock act as a normal file, the attribute calls fail
+}
+
+// NewMockTTY returns a MockTTY in cooked mode with in as the input.
+func NewMockTTY(in string) *MockTTY {
+	m := &MockTTY{}
+	m.In.WriteString(in)
+	m.Termios.Sane()
+	m.Termios.Lflag |= ICANON | ECHO | ISIG
+	m.Termios.Cflag |= CS8
+	m.Termios.Cc[VMIN] = 1
+	return m
+}
+
+// Read reads from In.
+func (m *MockTTY) Read(b []byte) (int, error) {
+	return m.In.Read(b)
+}
+
+// Write writes to Out.
+func (m *MockTTY) Write(b []byte) (int, error) {
+	return m.Out.Write(b)
+}
+
+// Attr returns the current attributes.
+func (m *MockTTY) Attr() (Termios, error) {
+	if m.NotTTY {
+		return Termios{}, syscall.ENOTTY
+	}
+	return m.Termios, nil
+}
+
+// SetAttr sets the current attributes and records them in History.
+func (m *MockTTY) SetAttr(t *Termios) error {
+	if m.NotTTY {
+		return syscall.ENOTTY
+	}
+	m.Termios = *t
+	m.History = append(m.History, *t)
+	return nil
+}
+
+// Isatty returns true unless NotTTY is set.
+func (m *MockTTY) Isatty() bool {
+	return !m.NotTTY
+}
+
+// SetReadDeadline is a no-op, reads never block.
+func (m *MockTTY) SetReadDeadline(t time.Time) error {
+	return nil
+}
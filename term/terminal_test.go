@@ -0,0 +1,100 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"io"
+	"testing"
+)
+
+// TestMockTTYGetPass tests reading a password from a MockTTY.
+func TestMockTTYGetPass(t *testing.T) {
+	m := NewMockTTY("secret\n")
+	want := m.Termios
+	pass, err := GetPassTerminal("Pass:", m, make([]byte, 16))
+	if err != nil {
+		t.Fatalf("GetPassTerminal failed: %v", err)
+	}
+	if string(pass) != "secret" {
+		t.Errorf("GetPassTerminal got: %q want: %q", pass, "secret")
+	}
+	if got := m.Out.String(); got != "Pass:" {
+		t.Errorf("GetPassTerminal wrote: %q want: %q", got, "Pass:")
+	}
+	if len(m.History) != 2 {
+		t.Fatalf("GetPassTerminal set attributes %d times want: 2", len(m.History))
+	}
+	if m.History[0].Lflag&ECHO != 0 {
+		t.Errorf("GetPassTerminal read with ECHO on")
+	}
+	if m.Termios != want {
+		t.Errorf("GetPassTerminal left attributes: %v want: %v", m.Termios, want)
+	}
+	if pass, err := GetPassTerminal("Pass:", m, make([]byte, 16)); err != io.EOF || len(pass) != 0 {
+		t.Errorf("GetPassTerminal with no input got: %q,%v want: \"\",%v", pass, err, io.EOF)
+	}
+	m = NewMockTTY("secret\n")
+	m.NotTTY = true
+	if _, err := GetPassTerminal("Pass:", m, make([]byte, 16)); err == nil {
+		t.Errorf("GetPassTerminal of non tty got: <nil> want: error")
+	}
+	if m.Out.Len() != 0 {
+		t.Errorf("GetPassTerminal of non tty wrote: %q want: \"\"", m.Out.String())
+	}
+}
+
+// TestMockTTYModes tests the mode helpers on a MockTTY.
+func TestMockTTYModes(t *testing.T) {
+	m := NewMockTTY("")
+	cooked := m.Termios
+	restore, err := MakeRawTerminal(m)
+	if err != nil {
+		t.Fatalf("MakeRawTerminal failed: %v", err)
+	}
+	if m.Termios.Lflag&(ICANON|ECHO|ISIG) != 0 || m.Termios.Oflag&OPOST != 0 {
+		t.Errorf("MakeRawTerminal got: %v want raw mode", m.Termios)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	restore()
+	if m.Termios != cooked || len(m.History) != 2 {
+		t.Errorf("restore got: %v after %d changes want: %v after 2", m.Termios, len(m.History), cooked)
+	}
+	if err := SetEchoTerminal(m, false); err != nil || m.Termios.Lflag&ECHO != 0 {
+		t.Errorf("SetEchoTerminal(false) got: %v Lflag: %o want: <nil> no ECHO", err, m.Termios.Lflag)
+	}
+	if err := SetEchoTerminal(m, true); err != nil || m.Termios.Lflag&ECHO == 0 {
+		t.Errorf("SetEchoTerminal(true) got: %v Lflag: %o want: <nil> ECHO", err, m.Termios.Lflag)
+	}
+	tios := m.Termios
+	if err := tios.SetCbreakTerminal(m); err != nil || m.Termios.Lflag&(ICANON|ECHO) != 0 || m.Termios.Lflag&ISIG == 0 {
+		t.Errorf("SetCbreakTerminal got: %v Lflag: %o want: <nil> ISIG only", err, m.Termios.Lflag)
+	}
+	if err := tios.SetCookedTerminal(m); err != nil || m.Termios.Lflag&(ICANON|ECHO|ISIG) != ICANON|ECHO|ISIG {
+		t.Errorf("SetCookedTerminal got: %v Lflag: %o want: <nil> ICANON ECHO ISIG", err, m.Termios.Lflag)
+	}
+	saved := m.Termios
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("GuardTerminal recovered: %v want: boom", r)
+			}
+		}()
+		defer GuardTerminal(m)()
+		m.Termios.Raw()
+		panic("boom")
+	}()
+	if m.Termios != saved {
+		t.Errorf("GuardTerminal left attributes: %v want: %v", m.Termios, saved)
+	}
+	m.NotTTY = true
+	if m.Isatty() {
+		t.Errorf("Isatty with NotTTY got: true want: false")
+	}
+	if _, err := MakeRawTerminal(m); err == nil {
+		t.Errorf("MakeRawTerminal of non tty got: <nil> want: error")
+	}
+}
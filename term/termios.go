@@ -12,7 +12,8 @@ screen, tmux, and expect.
 The Termios type is used for setting/getting Terminal capabilities while
 the PTY type is used for handling virtual terminals.
 
-Currently this part of this lib is Linux specific.
+The Termios and PTY parts are implemented for Linux, Darwin and FreeBSD.
+On Windows only Isatty and Winsz work, the rest returns ErrUnsupported.
 
 Also implements a simple version of readline in pure Go and some Stringers
 for terminal colors and attributes.
@@ -20,93 +21,65 @@ for terminal colors and attributes.
 package term
 
 import (
+	"errors"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-// INPUT handling terminal flags
-// see 'man stty' for further info about most of the constants
-const (
-	IGNBRK  = 0000001 // IGNBRK ignore break characters
-	BRKINT  = 0000002 // BRKINT Break genereates an interrupt signal
-	IGNPAR  = 0000004 // IGNPAR Ignore characters with parity errors
-	PARMRK  = 0000010 // PARMRK Mark parity errors byte{ff,0}
-	INPCK   = 0000020 // INPCK enable parity checking
-	ISTRIP  = 0000040 // ISTRIP Clear 8th bit of input characters
-	INLCR   = 0000100 // INLCR Translate LF => CR
-	IGNCR   = 0000200 // IGNCR Ignore Carriage Return
-	ICRNL   = 0000400 // ICRNL Translate CR => NL
-	IUCLC   = 0001000 // IUCLC Translate uppercase to lowercase
-	IXON    = 0002000 // IXON Enable flow control
-	IXANY   = 0004000 // IXANY let any char restart input
-	IXOFF   = 0010000 // IXOFF start sending start/stop chars
-	IMAXBEL = 0020000 // IMAXBEL Sound the bell and skip flushing input buffer
-	IUTF8   = 0040000 // IUTF8 assume input being utf-8
+// Errors returned by this package.
+var (
+	ErrUnsupported  = errors.New("not supported on this platform") // ErrUnsupported functionality not available on the current platform
+	ErrNoPTY        = errors.New("no PTY")                         // ErrNoPTY the PTY is nil
+	ErrNoPTMX       = errors.New("no PTY multiplexer device")      // ErrNoPTMX no /dev/ptmx to open PTYs with, the error also matches ErrNoPTY
+	ErrNilMaster    = errors.New("Master FD nil")                  // ErrNilMaster the PTY Master is nil
+	ErrNilSlave     = errors.New("Slave FD nil")                   // ErrNilSlave the PTY Slave is nil
+	ErrTimeout      = errors.New("timed out waiting for terminal") // ErrTimeout the terminal didn't reply or send input in time
+	ErrMismatch     = errors.New("passwords don't match")          // ErrMismatch the password and its confirmation differ
+	ErrNoProcess    = errors.New("no process started")             // ErrNoProcess PTY.Wait without a process started by PTY.Run
+	ErrInterrupted  = errors.New("interrupted")                    // ErrInterrupted Ctrl-C typed while reading a line
+	ErrNotATerminal = errors.New("not a terminal")                 // ErrNotATerminal the file isn't a tty
 )
 
-// OUTPUT treatment terminal flags
-const (
-	OPOST  = 0000001 // OPOST post process output
-	OLCUC  = 0000002 // OLCUC translate lower case to upper case
-	ONLCR  = 0000004 // ONLCR Map NL -> CR-NL
-	OCRNL  = 0000010 // OCRNL Map CR -> NL
-	ONOCR  = 0000020 // ONOCR No CR at col 0
-	ONLRET = 0000040 // ONLRET NL also do CR
-	OFILL  = 0000100 // OFILL Fillchar for delay
-	OFDEL  = 0000200 // OFDEL use delete instead of null
-)
+// CloseError is returned when closing either side of a PTY fails.
+// Master and Slave holds the error from respective side, nil if that side closed fine.
+type CloseError struct {
+	Master error // Master error closing the PTY Master
+	Slave  error // Slave error closing the PTY Slave
+}
 
-// TERM control modes.
-const (
-	CSIZE  = 0000060 // CSIZE used as mask when setting character size
-	CS5    = 0000000 // CS5 char size 5bits
-	CS6    = 0000020 // CS6 char size 6bits
-	CS7    = 0000040 // CS7 char size 7bits
-	CS8    = 0000060 // CS8 char size 8bits
-	CSTOPB = 0000100 // CSTOPB two stop bits
-	CREAD  = 0000200 // CREAD enable input
-	PARENB = 0000400 // PARENB generate and expect parity bit
-	PARODD = 0001000 // PARODD set odd parity
-	HUPCL  = 0002000 // HUPCL send HUP when last process closes term
-	CLOCAL = 0004000 // CLOCAL no modem control signals
-)
+// Error implements the error interface.
+func (e *CloseError) Error() string {
+	var errs []string
+	if e.Slave != nil {
+		errs = append(errs, "Slave: "+e.Slave.Error())
+	}
+	if e.Master != nil {
+		errs = append(errs, "Master: "+e.Master.Error())
+	}
+	return strings.Join(errs, " ")
+}
 
-// TERM modes
-const (
-	ISIG    = 0000001 // ISIG enable Interrupt,quit and suspend chars
-	ICANON  = 0000002 // ICANON enable erase,kill ,werase and rprnt chars
-	XCASE   = 0000004 // XCASE preceedes all uppercase chars with '\'
-	ECHO    = 0000010 // ECHO echo input characters
-	ECHOE   = 0000020 // ECHOE erase => BS - SPACE - BS
-	ECHOK   = 0000040 // ECHOK add newline after kill char
-	ECHONL  = 0000100 // ECHONL echo NL even without other characters
-	NOFLSH  = 0000200 // NOFLSH no flush after interrupt and kill characters
-	TOSTOP  = 0000400 // TOSTOP stop BG jobs trying to write to term
-	ECHOCTL = 0001000 // ECHOCTL will echo control characters as ^c
-	ECHOPRT = 0002000 // ECHOPRT will print erased characters between \ /
-	ECHOKE  = 0004000 // ECHOKE kill all line considering ECHOPRT and ECHOE flags
-	IEXTEN  = 0100000 // IEXTEN enable non POSIX special characters
-)
+// Unwrap gives errors.Is and errors.As access to the Master and Slave errors.
+func (e *CloseError) Unwrap() []error {
+	var errs []error
+	if e.Slave != nil {
+		errs = append(errs, e.Slave)
+	}
+	if e.Master != nil {
+		errs = append(errs, e.Master)
+	}
+	return errs
+}
 
-// Control characters
+// Control characters, the indexes into Cc are set per platform in controlchar_*.go.
 const (
-	VINTR    = 0  // VINTR 		char will send an interrupt signal
-	VQUIT    = 1  // VQUIT 		char will send a quit signal
-	VERASE   = 2  // VEREASE 	char will erase last typed char
-	VKILL    = 3  // VKILL 		char will erase current line
-	VEOF     = 4  // VEOF 		char will send EOF
-	VTIME    = 5  // VTIME 		set read timeout in tenths of seconds
-	VMIN     = 6  // VMIN 		set min characters for a complete read
-	VSWTC    = 7  // VSWTC 		char will switch to a different shell layer
-	VSTART   = 8  // VSTART 	char will restart output after stopping it
-	VSTOP    = 9  // VSTOP 		char will stop output
-	VSUSP    = 10 // VSUSP 		char will send a stop signal
-	VEOL     = 11 // VEOL 		char will end the line
-	VREPRINT = 12 // VREPRINT will redraw the current line
-	VDISCARD = 13 // VDISCARD
-	VWERASE  = 14 // VWERASE 	char will erase last word typed
-	VLNEXT   = 15 // VLNEXT 	char will enter the next char quoted
-	VEOL2    = 16 // VEOL2 		char alternate to end line
-	tNCCS    = 32 // tNCCS    Termios CC size
+	tNCCS = 32 // tNCCS Termios CC size
 )
 
 // Termios merge of the C Terminal and Kernel termios structs.
@@ -144,6 +117,10 @@ type Winsize struct {
 type PTY struct {
 	Master *os.File // Master The Master part of the PTY
 	Slave  *os.File // Slave The Slave part of the PTY
+
+	masterOnly bool      // masterOnly PTY from OpenPTYMaster or after CloseSlave, a nil Slave is expected
+	slaveOnly  bool      // slaveOnly after CloseMaster, a nil Master is expected
+	cmd        *exec.Cmd // cmd the command started by Run for Wait
 }
 
 // Raw Sets terminal t to raw mode.
@@ -178,6 +155,283 @@ func (t *Termios) Sane() {
 	t.Cflag |= CREAD
 }
 
+// saneCc are the control characters set by Reset, the usual ^C, ^\, DEL and so on.
+var saneCc = map[int]byte{
+	VINTR:    0x03,
+	VQUIT:    0x1c,
+	VERASE:   0x7f,
+	VKILL:    0x15,
+	VEOF:     0x04,
+	VSTART:   0x11,
+	VSTOP:    0x13,
+	VSUSP:    0x1a,
+	VREPRINT: 0x12,
+	VDISCARD: 0x0f,
+	VWERASE:  0x17,
+	VLNEXT:   0x16,
+	VMIN:     1,
+	VTIME:    0,
+}
+
+// Reset puts the terminal f back to sane settings whatever state it was left in, for when a
+// crashed program left it in a weird mode. Same as "stty sane" but only for f.
+// Line editing, echo, signals and output processing are turned on and the control characters
+// set to their usual values. The speed, character size and IUTF8 are kept.
+func Reset(f *os.File) error {
+	t, err := Attr(f)
+	if err != nil {
+		return err
+	}
+	t.Iflag = t.Iflag&IUTF8 | BRKINT | ICRNL | IMAXBEL | IXON
+	t.Oflag = OPOST | ONLCR
+	t.Lflag = ISIG | ICANON | IEXTEN | ECHO | ECHOE | ECHOK | ECHOCTL | ECHOKE
+	t.Cflag |= CREAD
+	for i, c := range saneCc {
+		t.Cc[i] = c
+	}
+	return t.Set(f)
+}
+
+// SetNow Sets terminal t attributes on file right away, same as Set.
+// Use SetDrain or SetFlush to wait for pending output when switching modes mid-stream.
+func (t *Termios) SetNow(file *os.File) error {
+	return t.Set(file)
+}
+
+// SetCbreak sets terminal t to cbreak mode and applies it to file.
+// Input is no longer line buffered or echoed but signal generation and output processing are kept.
+// t is left holding the applied attributes so it can be stashed for later restoration.
+func (t *Termios) SetCbreak(file *os.File) error {
+	return t.SetCbreakTerminal(NewTerminal(file))
+}
+
+// SetCbreakTerminal sets terminal t to cbreak mode and applies it to the Terminal term, same as SetCbreak.
+func (t *Termios) SetCbreakTerminal(term Terminal) error {
+	t.Lflag &^= ICANON | ECHO
+	t.Cc[VMIN] = 1
+	t.Cc[VTIME] = 0
+	return term.SetAttr(t)
+}
+
+// SetCooked turns line buffering, echo and signal generation back on for terminal t and applies it to file.
+// t is left holding the applied attributes.
+func (t *Termios) SetCooked(file *os.File) error {
+	return t.SetCookedTerminal(NewTerminal(file))
+}
+
+// SetCookedTerminal turns cooked mode back on for terminal t and applies it to the Terminal term, same as SetCooked.
+func (t *Termios) SetCookedTerminal(term Terminal) error {
+	t.Lflag |= ICANON | ECHO | ISIG
+	return term.SetAttr(t)
+}
+
+// SetEcho turns echo of input characters on or off for terminal t.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetEcho(on bool) {
+	if on {
+		t.Lflag |= ECHO
+		return
+	}
+	t.Lflag &^= ECHO
+}
+
+// SetOutputProcessing turns output post-processing, OPOST, on or off for terminal t.
+// With it off what's written goes out as is, eg. for TUI code sending its own "\r\n".
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetOutputProcessing(on bool) {
+	if on {
+		t.Oflag |= OPOST
+		return
+	}
+	t.Oflag &^= OPOST
+}
+
+// SetNLCR turns the mapping of NL to CR-NL on output, ONLCR, on or off for terminal t.
+// The mapping is only done with output processing on, see SetOutputProcessing.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetNLCR(on bool) {
+	if on {
+		t.Oflag |= ONLCR
+		return
+	}
+	t.Oflag &^= ONLCR
+}
+
+// SetEchoFile turns echo of input characters on or off for the terminal f.
+func SetEchoFile(f *os.File, on bool) error {
+	return SetEchoTerminal(NewTerminal(f), on)
+}
+
+// SetEchoTerminal turns echo of input characters on or off for the Terminal term.
+func SetEchoTerminal(term Terminal, on bool) error {
+	t, err := term.Attr()
+	if err != nil {
+		return err
+	}
+	t.SetEcho(on)
+	return term.SetAttr(&t)
+}
+
+// CopyAttr copies all the attributes of the terminal src to the terminal dst.
+// Use it to give a PTY the same mode as the real terminal, CopySize does the window size.
+func CopyAttr(dst, src *os.File) error {
+	t, err := Attr(src)
+	if err != nil {
+		return err
+	}
+	return t.Set(dst)
+}
+
+// SetVMin sets the minimum number of bytes a read on terminal t waits for.
+// VMIN and VTIME only take effect in non-canonical mode, with ICANON cleared.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetVMin(n byte) {
+	t.Cc[VMIN] = n
+}
+
+// VMin returns the minimum number of bytes a read on terminal t waits for.
+func (t *Termios) VMin() byte {
+	return t.Cc[VMIN]
+}
+
+// SetVTime sets the read timeout of terminal t in tenths of a second.
+// With VMIN=0 it's the time a read waits for any input, with VMIN>0 the time allowed between bytes.
+// VMIN and VTIME only take effect in non-canonical mode, with ICANON cleared.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetVTime(n byte) {
+	t.Cc[VTIME] = n
+}
+
+// VTime returns the read timeout of terminal t in tenths of a second.
+func (t *Termios) VTime() byte {
+	return t.Cc[VTIME]
+}
+
+// SetSoftwareFlow turns XON/XOFF flow control on or off for terminal t.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetSoftwareFlow(on bool) {
+	if on {
+		t.Iflag |= IXON | IXOFF
+		return
+	}
+	t.Iflag &^= IXON | IXOFF
+}
+
+// SetHardwareFlow turns RTS/CTS flow control on or off for terminal t.
+// Only t is changed, use Set to apply it.
+func (t *Termios) SetHardwareFlow(on bool) {
+	if on {
+		t.Cflag |= CRTSCTS
+		return
+	}
+	t.Cflag &^= CRTSCTS
+}
+
+// FlowControl returns if software (XON/XOFF) and hardware (RTS/CTS) flow control is on for the terminal f.
+func FlowControl(f *os.File) (soft, hard bool, err error) {
+	t, err := Attr(f)
+	if err != nil {
+		return false, false, err
+	}
+	return t.Iflag&(IXON|IXOFF) != 0, t.Cflag&CRTSCTS != 0, nil
+}
+
+// MakeRaw puts the terminal f into raw mode and returns a function restoring the previous attributes.
+// The restore function only re-applies the saved attributes the first time it's called, calling it
+// again is a no-op.
+//
+//	restore, err := term.MakeRaw(os.Stdin)
+//	if err != nil {
+//		return err
+//	}
+//	defer restore()
+func MakeRaw(f *os.File) (restore func() error, err error) {
+	return MakeRawTerminal(NewTerminal(f))
+}
+
+// MakeRawTerminal puts the Terminal term into raw mode, same as MakeRaw.
+func MakeRawTerminal(term Terminal) (restore func() error, err error) {
+	backup, err := term.Attr()
+	if err != nil {
+		return nil, err
+	}
+	raw := backup
+	raw.Raw()
+	if err := term.SetAttr(&raw); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() {
+			err = term.SetAttr(&backup)
+		})
+		return err
+	}, nil
+}
+
+// Guard saves the attributes of the terminal f and returns a function restoring them.
+// Meant to be deferred, if a panic is in flight the terminal is restored before panicking again.
+// If the attributes of f can't be read the returned function only passes on panics.
+//
+//	defer term.Guard(os.Stdin)()
+func Guard(f *os.File) func() {
+	return GuardTerminal(NewTerminal(f))
+}
+
+// GuardTerminal saves the attributes of the Terminal term and returns a function restoring them, same as Guard.
+func GuardTerminal(term Terminal) func() {
+	backup, err := term.Attr()
+	return func() {
+		r := recover()
+		if err == nil {
+			term.SetAttr(&backup)
+		}
+		if r != nil {
+			panic(r)
+		}
+	}
+}
+
+// RestoreOnSignal saves the attributes of the terminal f and restores them when one of sigs arrives,
+// SIGINT and SIGTERM if none are given. After restoring the signal is raised again so the program
+// dies the way it would have, unless something else is also handling it.
+// The returned function removes the handler, call it once the terminal is back to normal.
+// If the attributes of f can't be read nothing is installed.
+//
+//	defer term.RestoreOnSignal(os.Stdin)()
+func RestoreOnSignal(f *os.File, sigs ...os.Signal) func() {
+	backup, err := Attr(f)
+	if err != nil {
+		return func() {}
+	}
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, sigs...)
+	go func() {
+		select {
+		case s := <-sig:
+			backup.Set(f)
+			// With no one else notified the default action is back after Stop.
+			signal.Stop(sig)
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				p.Signal(s)
+			}
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(done)
+		})
+	}
+}
+
 // GetChar reads a single byte.
 func GetChar(f *os.File) (b byte, err error) {
 	bs := make([]byte, 1, 1)
@@ -187,6 +441,65 @@ func GetChar(f *os.File) (b byte, err error) {
 	return bs[0], err
 }
 
+// ReadWithTimeout reads into b from f giving up with ErrTimeout if nothing arrived within d.
+// For a tty the input is waited for with WaitReadable, in canonical mode that's a complete line.
+// When not canonical VMIN and VTIME are cleared for the read so it returns what is there, the
+// prior attributes are restored after.
+// Other files need to support read deadlines, see os.File.SetReadDeadline.
+// With d <= 0 it's a plain blocking read.
+func ReadWithTimeout(f *os.File, b []byte, d time.Duration) (int, error) {
+	if d <= 0 {
+		return f.Read(b)
+	}
+	deadline := time.Now().Add(d)
+	// Only character devices can be ttys, for others Attr is skipped as getting the fd puts
+	// f in blocking mode and the read deadline stops working.
+	if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		if t, err := Attr(f); err == nil {
+			return readTTYWithTimeout(f, t, b, deadline)
+		}
+	}
+	f.SetReadDeadline(deadline)
+	defer f.SetReadDeadline(time.Time{})
+	n, err := f.Read(b)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return n, ErrTimeout
+	}
+	return n, err
+}
+
+// readTTYWithTimeout is ReadWithTimeout for the tty f with the attributes t.
+func readTTYWithTimeout(f *os.File, t Termios, b []byte, deadline time.Time) (int, error) {
+	canonical := t.Lflag&ICANON != 0
+	if !canonical {
+		defer t.Set(f)
+		timed := t
+		timed.Cc[VMIN] = 0
+		timed.Cc[VTIME] = 0
+		if err := timed.Set(f); err != nil {
+			return 0, err
+		}
+	}
+	for {
+		wait := time.Until(deadline)
+		if wait < 0 {
+			wait = 0
+		}
+		if _, err := WaitReadable([]*os.File{f}, wait); err != nil {
+			return 0, err
+		}
+		n, err := f.Read(b)
+		// Nothing read when not canonical is no input yet rather than EOF, eg. another reader got it first.
+		if n == 0 && err == io.EOF && !canonical {
+			if time.Now().Before(deadline) {
+				continue
+			}
+			return 0, ErrTimeout
+		}
+		return n, err
+	}
+}
+
 // ReadByte implements the io.ByteReader interface to read single char from the PTY.
 func (p *PTY) ReadByte() (byte, error) {
 	bs := make([]byte, 1, 1)
@@ -198,3 +511,195 @@ func (p *PTY) ReadByte() (byte, error) {
 func (p *PTY) GetChar() (byte, error) {
 	return p.ReadByte()
 }
+
+// SetRaw puts the PTY in raw mode so everything passes through untouched.
+// The Master of a PTY isn't a terminal itself, it's the Slave attributes that decide what's
+// processed on the way through so that's where raw mode is set.
+func (p *PTY) SetRaw() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	if p.Slave == nil {
+		return ErrNilSlave
+	}
+	t, err := Attr(p.Slave)
+	if err != nil {
+		return err
+	}
+	t.Raw()
+	return t.Set(p.Slave)
+}
+
+// SetSlaveEcho turns echo on or off for the PTY, with it off what's written to the Master isn't
+// echoed back, eg. a password fed to the child. Like Resize the Master is used for a PTY from
+// OpenPTYMaster, the attributes set through it are the ones of the Slave.
+func (p *PTY) SetSlaveEcho(on bool) error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	f := p.Slave
+	if f == nil {
+		f = p.Master
+	}
+	if f == nil {
+		return ErrNilSlave
+	}
+	return SetEchoFile(f, on)
+}
+
+// OpenPTYRaw Creates a new Master/Slave PTY pair in raw mode, see SetRaw.
+func OpenPTYRaw() (*PTY, error) {
+	p, err := OpenPTY()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.SetRaw(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// OpenPTYWith Creates a new Master/Slave PTY pair with the window size set to rows and cols and,
+// if attrs isn't nil, the Slave attributes set to attrs. Both are in place before OpenPTYWith
+// returns so a child started on the Slave never sees the defaults.
+func OpenPTYWith(rows, cols int, attrs *Termios) (*PTY, error) {
+	p, err := OpenPTY()
+	if err != nil {
+		return nil, err
+	}
+	if err := SetSize(p.Slave, rows, cols); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if attrs != nil {
+		if err := attrs.Set(p.Slave); err != nil {
+			p.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// OpenPTYOwned Creates a new Master/Slave PTY pair with the Slave device owned by uid and gid and
+// its permissions set to mode, for handing the Slave to another user. A uid or gid of -1 is left
+// as set by grantpt. Changing the owner normally takes root, without the privilege the error
+// matches os.ErrPermission.
+func OpenPTYOwned(uid, gid int, mode os.FileMode) (*PTY, error) {
+	p, err := OpenPTY()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.PTSName()
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+	if err := os.Chown(name, uid, gid); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if err := os.Chmod(name, mode); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Read implements the io.Reader interface reading from the PTY master.
+// This is what was written to the slave.
+func (p *PTY) Read(b []byte) (int, error) {
+	return p.Master.Read(b)
+}
+
+// IsPTYClosed returns true if err, from reading the Master of a PTY, means the Slave isn't open
+// anymore, the normal end of a session when the program on it exits. Linux gives EIO for that,
+// Darwin and FreeBSD io.EOF, both are taken as closed so a copy loop can end cleanly on either.
+//
+//	if _, err := io.Copy(os.Stdout, pty.Master); err != nil && !term.IsPTYClosed(err) {
+//		return err
+//	}
+func IsPTYClosed(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, io.EOF)
+}
+
+// Write implements the io.Writer interface writing to the PTY master.
+// The slave reads it as input typed on the terminal.
+func (p *PTY) Write(b []byte) (int, error) {
+	return p.Master.Write(b)
+}
+
+// IoctlError records a failed IOCTL and the operation it was used for.
+type IoctlError struct {
+	Op  string  // Op name of the IOCTL eg. TIOCGWINSZ
+	Cmd uintptr // Cmd IOCTL request number
+	Err error   // Err the underlying error, normally a syscall.Errno
+}
+
+// Error implements the error interface.
+func (e *IoctlError) Error() string {
+	return "ioctl " + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *IoctlError) Unwrap() error {
+	return e.Err
+}
+
+// Close closes the PTYs that OpenPTY created.
+// Failing to close any side gives a *CloseError, a nil side is reported as ErrNilMaster/ErrNilSlave.
+// The nil Slave of a PTY from OpenPTYMaster is not reported, nor a side already closed by
+// CloseSlave or CloseMaster.
+func (p *PTY) Close() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	slaveErr := ErrNilSlave
+	switch {
+	case p.Slave != nil:
+		slaveErr = p.Slave.Close()
+	case p.masterOnly:
+		slaveErr = nil
+	}
+	masterErr := ErrNilMaster
+	switch {
+	case p.Master != nil:
+		masterErr = p.Master.Close()
+	case p.slaveOnly:
+		masterErr = nil
+	}
+	if slaveErr != nil || masterErr != nil {
+		return &CloseError{Master: masterErr, Slave: slaveErr}
+	}
+	return nil
+}
+
+// CloseSlave closes only the Slave of the PTY setting it to nil, eg. in the parent once the
+// Slave is handed to a child process. Close after this only closes the Master.
+func (p *PTY) CloseSlave() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	if p.Slave == nil {
+		return ErrNilSlave
+	}
+	err := p.Slave.Close()
+	p.Slave = nil
+	p.masterOnly = true
+	return err
+}
+
+// CloseMaster closes only the Master of the PTY setting it to nil.
+// Close after this only closes the Slave.
+func (p *PTY) CloseMaster() error {
+	if p == nil {
+		return ErrNoPTY
+	}
+	if p.Master == nil {
+		return ErrNilMaster
+	}
+	err := p.Master.Close()
+	p.Master = nil
+	p.slaveOnly = true
+	return err
+}
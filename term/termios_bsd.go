@@ -0,0 +1,72 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd
+
+package term
+
+import (
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// SetDrain Sets terminal t attributes on file once all output written to it has been transmitted.
+func (t *Termios) SetDrain(file *os.File) error {
+	kt := t.toKernel()
+	return ioctl("TIOCSETAW", file.Fd(), syscall.TIOCSETAW, uintptr(unsafe.Pointer(&kt)))
+}
+
+// SetFlush Sets terminal t attributes on file once all output written to it has been transmitted,
+// input received but not read is discarded.
+func (t *Termios) SetFlush(file *os.File) error {
+	kt := t.toKernel()
+	return ioctl("TIOCSETAF", file.Fd(), syscall.TIOCSETAF, uintptr(unsafe.Pointer(&kt)))
+}
+
+// FREAD and FWRITE from <sys/fcntl.h>, used to pick the TIOCFLUSH queues.
+const (
+	fread  = 0x0001
+	fwrite = 0x0002
+)
+
+// tcflush discards the which queue of f, TIOCFLUSH takes the queues as FREAD/FWRITE bits.
+func tcflush(f *os.File, which int) error {
+	var q int32
+	switch which {
+	case FlushInput:
+		q = fread
+	case FlushOutput:
+		q = fwrite
+	case FlushBoth:
+		q = fread | fwrite
+	}
+	return ioctl("TIOCFLUSH", f.Fd(), syscall.TIOCFLUSH, uintptr(unsafe.Pointer(&q)))
+}
+
+// tcdrain waits for the output of f to be transmitted.
+func tcdrain(f *os.File) error {
+	return ioctl("TIOCDRAIN", f.Fd(), syscall.TIOCDRAIN, 0)
+}
+
+// setBreak turns the break on f on or off.
+func setBreak(f *os.File, on bool) error {
+	if on {
+		return ioctl("TIOCSBRK", f.Fd(), syscall.TIOCSBRK, 0)
+	}
+	return ioctl("TIOCCBRK", f.Fd(), syscall.TIOCCBRK, 0)
+}
+
+// poll waits up to timeout for the events of fds, a negative timeout waits for as long as it takes.
+func poll(fds []pollFd, timeout time.Duration) error {
+	ms := -1
+	if timeout >= 0 {
+		ms = int((timeout + time.Millisecond - 1) / time.Millisecond)
+	}
+	if _, _, e := syscall.Syscall(syscall.SYS_POLL, uintptr(unsafe.Pointer(&fds[0])), uintptr(len(fds)), uintptr(ms)); e != 0 {
+		return e
+	}
+	return nil
+}
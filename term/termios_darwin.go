@@ -1,3 +1,5 @@
+//go:build darwin
+
 package term
 
 import (
@@ -11,17 +13,28 @@ import (
 
 // IOCTL terminal stuff.
 const (
-	TCGETS       = 0x5401     // TCGETS get terminal attributes
-	TCSETS       = 0x5402     // TCSETS set terminal attributes
-	TIOCGWINSZ   = 0x5413     // TIOCGWINSZ used to get the terminal window size
-	TIOCSWINSZ   = 0x5414     // TIOCSWINSZ used to set the terminal window size
-	TIOCGPTN     = 0x80045430 // TIOCGPTN IOCTL used to get the PTY number
-	TIOCSPTLCK   = 0x40045431 // TIOCSPTLCK IOCT used to lock/unlock PTY
-	CBAUD        = 0o010017   // CBAUD Serial speed settings
-	CBAUDEX      = 0o010000   // CBAUDX Serial speed settings
-	TIOCPTMASTER = 0x2000741c
-	// FreeBSD posix_openpt syscall.
-	OPENPT = 504
+	TCGETS     = syscall.TIOCGETA   // TCGETS get terminal attributes
+	TCSETS     = syscall.TIOCSETA   // TCSETS set terminal attributes
+	TIOCGWINSZ = syscall.TIOCGWINSZ // TIOCGWINSZ used to get the terminal window size
+	TIOCSWINSZ = syscall.TIOCSWINSZ // TIOCSWINSZ used to set the terminal window size
+	TIOCGPTN   = 0x80045430         // TIOCGPTN the Linux IOCTL getting the PTY number, Darwin doesn't have it
+	TIOCSPTLCK = 0x40045431         // TIOCSPTLCK IOCT used to lock/unlock PTY
+	CBAUD      = 0o010017           // CBAUD Serial speed settings
+	CBAUDEX    = 0o010000           // CBAUDX Serial speed settings
+	TIOCMGET   = syscall.TIOCMGET   // TIOCMGET IOCTL used to get the modem bits
+	TIOCMBIS   = syscall.TIOCMBIS   // TIOCMBIS IOCTL used to set modem bits
+	TIOCMBIC   = syscall.TIOCMBIC   // TIOCMBIC IOCTL used to clear modem bits
+	FIONREAD   = 0x4004667f         // FIONREAD IOCTL used to get the number of bytes waiting to be read
+)
+
+// CRTSCTS RTS/CTS hardware flow control.
+const CRTSCTS = 0o600000
+
+// Flags Darwin has and FreeBSD doesn't.
+const (
+	IUTF8 = 0x00004000 // IUTF8 assume input being utf-8
+	OFILL = 0x00000080 // OFILL Fillchar for delay
+	OFDEL = 0x00020000 // OFDEL use delete instead of null
 )
 
 // from <sys/ioccom.h>
@@ -36,14 +49,6 @@ const (
 	_IOC_PARAM_MASK  = (1 << _IOC_PARAM_SHIFT) - 1
 )
 
-func ioctl(fd, cmd, ptr uintptr) error {
-	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, ptr)
-	if e != 0 {
-		return e
-	}
-	return nil
-}
-
 func _IOC_PARM_LEN(ioctl uintptr) uintptr {
 	return (ioctl >> 16) & _IOC_PARAM_MASK
 }
@@ -56,170 +61,107 @@ func _IO(group byte, ioctl_num uintptr) uintptr {
 	return _IOC(_IOC_VOID, group, ioctl_num, 0)
 }
 
+// toKernel converts t to the Darwin kernel termios struct.
+// The Darwin struct uses 64bit flags, a shorter Cc array and has no Line.
+func (t *Termios) toKernel() syscall.Termios {
+	kt := syscall.Termios{
+		Iflag:  uint64(t.Iflag),
+		Oflag:  uint64(t.Oflag),
+		Cflag:  uint64(t.Cflag),
+		Lflag:  uint64(t.Lflag),
+		Ispeed: uint64(t.Ispeed),
+		Ospeed: uint64(t.Ospeed),
+	}
+	copy(kt.Cc[:], t.Cc[:])
+	return kt
+}
+
+// fromKernel fills in t from the Darwin kernel termios struct.
+func (t *Termios) fromKernel(kt *syscall.Termios) {
+	t.Iflag = uint32(kt.Iflag)
+	t.Oflag = uint32(kt.Oflag)
+	t.Cflag = uint32(kt.Cflag)
+	t.Lflag = uint32(kt.Lflag)
+	copy(t.Cc[:], kt.Cc[:])
+	t.Ispeed = uint32(kt.Ispeed)
+	t.Ospeed = uint32(kt.Ospeed)
+}
+
 // Set Sets terminal t attributes on file.
 func (t *Termios) Set(file *os.File) error {
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCSETS), uintptr(unsafe.Pointer(t)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
+	kt := t.toKernel()
+	return ioctl("TIOCSETA", file.Fd(), TCSETS, uintptr(unsafe.Pointer(&kt)))
 }
 
 // Attr Gets (terminal related) attributes from file.
 func Attr(file *os.File) (Termios, error) {
 	var t Termios
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCGETS), uintptr(unsafe.Pointer(&t)))
-	if errno != 0 {
-		return t, errno
+	var kt syscall.Termios
+	if err := ioctl("TIOCGETA", file.Fd(), TCGETS, uintptr(unsafe.Pointer(&kt))); err != nil {
+		return t, err
 	}
-	t.Ispeed &= CBAUD | CBAUDEX
-	t.Ospeed &= CBAUD | CBAUDEX
+	t.fromKernel(&kt)
 	return t, nil
 }
 
-// Isatty returns true if file is a tty.
-func Isatty(file *os.File) bool {
-	_, err := Attr(file)
-	return err == nil
-}
-
-// GetPass reads password from a TTY with no echo.
-func GetPass(prompt string, f *os.File, pbuf []byte) ([]byte, error) {
-	t, err := Attr(f)
-	if err != nil {
-		return nil, err
-	}
-	defer t.Set(f)
-	noecho := t
-	noecho.Lflag = noecho.Lflag &^ ECHO
-	if err := noecho.Set(f); err != nil {
-		return nil, err
-	}
-	b := make([]byte, 1, 1)
-	i := 0
-	if _, err := f.Write([]byte(prompt)); err != nil {
-		return nil, err
-	}
-	for ; i < len(pbuf); i++ {
-		if _, err := f.Read(b); err != nil {
-			b[0] = 0
-			clearbuf(pbuf[:i+1])
-		}
-		if b[0] == '\n' || b[0] == '\r' {
-			return pbuf[:i], nil
-		}
-		pbuf[i] = b[0]
-		b[0] = 0
-	}
-	clearbuf(pbuf[:i+1])
-	return nil, errors.New("ran out of bufferspace")
-}
-
-// clearbuf clears out the buffer incase we couldn't read the full password.
-func clearbuf(b []byte) {
-	for i := range b {
-		b[i] = 0
-	}
-}
-
-// Winsz Fetches the current terminal windowsize.
-// example handling changing window sizes with PTYs:
-//
-// import "os"
-// import "os/signal"
-//
-// var sig = make(chan os.Signal,2) 		// Channel to listen for UNIX SIGNALS on
-// signal.Notify(sig, syscall.SIGWINCH) // That'd be the window changing
-//
-//	for {
-//		<-sig
-//		term.Winsz(os.Stdin)			// We got signaled our terminal changed size so we read in the new value
-//	 term.Setwinsz(pty.Slave) // Copy it to our virtual Terminal
-//	}
-func (t *Termios) Winsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCGWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
-}
-
-// Setwinsz Sets the terminal window size.
-func (t *Termios) Setwinsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCSWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
-}
-
-type dname struct {
-	len int
-	buf unsafe.Pointer
-}
-
-const (
-	pathDev = "/dev/ptmx"
-)
-
 // OpenPTY Creates a new Master/Slave PTY pair.
 func OpenPTY() (*PTY, error) {
-	p, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	pty, err := OpenPTYMaster()
 	if err != nil {
 		return nil, err
 	}
 
-	sname, err := ptsname(p)
+	sname, err := pty.PTSName()
 	if err != nil {
+		pty.Master.Close()
 		return nil, err
 	}
 
-	err = grantpt(p)
+	pty.Slave, err = os.OpenFile(sname, os.O_RDWR|syscall.O_NOCTTY, 0)
 	if err != nil {
+		pty.Master.Close()
 		return nil, err
 	}
+	pty.masterOnly = false
+
+	return pty, nil
+}
 
-	err = unlockpt(p)
+// OpenPTYMaster Creates a new PTY only opening the Master, Slave is left nil.
+// The slave is granted and unlocked so it can be opened using the PTSName, eg. by a child process
+// that should get it as its controlling terminal.
+// /dev/ptmx is the only PTY multiplexer on Darwin, posix_openpt opens it too, so there is no
+// fallback. If it's missing the error matches ErrNoPTMX and ErrNoPTY.
+func OpenPTYMaster() (*PTY, error) {
+	master, err := openPTMX("/dev/ptmx")
 	if err != nil {
 		return nil, err
 	}
 
-	t, err := os.OpenFile(sname, os.O_RDWR, 0)
-	if err != nil {
+	if err := grantpt(master); err != nil {
+		master.Close()
+		return nil, err
+	}
+	if err := unlockpt(master); err != nil {
+		master.Close()
 		return nil, err
 	}
 
-	return &PTY{Master: p, Slave: t}, nil
+	return &PTY{Master: master, masterOnly: true}, nil
 }
 
 func grantpt(f *os.File) error {
-	return ioctl(f.Fd(), syscall.TIOCPTYGRANT, 0)
+	return ioctl("TIOCPTYGRANT", f.Fd(), syscall.TIOCPTYGRANT, 0)
 }
 
 func unlockpt(f *os.File) error {
-	return ioctl(f.Fd(), syscall.TIOCPTYUNLK, 0)
-}
-
-type winsize struct {
-	ws_row    uint16
-	ws_col    uint16
-	ws_xpixel uint16
-	ws_ypixel uint16
-}
-
-func setsize(f *os.File, rows uint16, cols uint16) error {
-	var ws winsize
-	ws.ws_row = rows
-	ws.ws_col = cols
-	return ioctl(f.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+	return ioctl("TIOCPTYUNLK", f.Fd(), syscall.TIOCPTYUNLK, 0)
 }
 
 func ptsname(f *os.File) (string, error) {
 	n := make([]byte, _IOC_PARM_LEN(syscall.TIOCPTYGNAME))
 
-	err := ioctl(f.Fd(), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&n[0])))
+	err := ioctl("TIOCPTYGNAME", f.Fd(), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&n[0])))
 	if err != nil {
 		return "", err
 	}
@@ -232,47 +174,43 @@ func ptsname(f *os.File) (string, error) {
 	return "", errors.New("TIOCPTYGNAME string not NUL-terminated")
 }
 
-// Close closes the PTYs that OpenPTY created.
-func (p *PTY) Close() error {
-	slaveErr := errors.New("Slave FD nil")
-	if p == nil {
-		return errors.New("no PTY")
-	}
-	if p.Slave != nil {
-		slaveErr = p.Slave.Close()
-	}
-	masterErr := errors.New("Master FD nil")
-	if p.Master != nil {
-		masterErr = p.Master.Close()
+// F_GETPATH fcntl used to get the path of a fd and MAXPATHLEN the size of buffer it fills in.
+const (
+	fGetPath   = 50
+	maxPathLen = 1024
+)
+
+// ttyname asks the kernel for the path of f using fcntl F_GETPATH.
+func ttyname(f *os.File) (string, error) {
+	buf := make([]byte, maxPathLen)
+	if _, _, e := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fGetPath, uintptr(unsafe.Pointer(&buf[0]))); e != 0 {
+		return "", e
 	}
-	if slaveErr != nil || masterErr != nil {
-		var errs []string
-		if slaveErr != nil {
-			errs = append(errs, "Slave: "+slaveErr.Error())
-		}
-		if masterErr != nil {
-			errs = append(errs, "Master: "+masterErr.Error())
+	for i, c := range buf {
+		if c == 0 {
+			return string(buf[:i]), nil
 		}
-		return errors.New(strings.Join(errs, " "))
 	}
-	return nil
+	return "", errors.New("F_GETPATH string not NUL-terminated")
 }
 
 // PTSName return the name of the pty.
+// Darwin has no /dev/pts, the slaves are named /dev/ttysNNN and the name is asked from the kernel.
 func (p *PTY) PTSName() (string, error) {
-	n, err := p.PTSNumber()
-	if err != nil {
-		return "", err
-	}
-	return "/dev/pts/" + strconv.Itoa(int(n)), nil
+	return ptsname(p.Master)
 }
 
 // PTSNumber return the pty number.
+// Darwin has no TIOCGPTN, the number is taken from the /dev/ttysNNN name TIOCPTYGNAME gives.
 func (p *PTY) PTSNumber() (uint, error) {
-	var ptyno uint
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(p.Master.Fd()), uintptr(TIOCGPTN), uintptr(unsafe.Pointer(&ptyno)))
-	if errno != 0 {
-		return 0, errno
+	name, err := ptsname(p.Master)
+	if err != nil {
+		return 0, err
+	}
+	num, ok := strings.CutPrefix(name, "/dev/ttys")
+	n, err := strconv.ParseUint(num, 10, 32)
+	if !ok || err != nil {
+		return 0, errors.New("pty name: " + strconv.Quote(name) + " not a valid /dev/ttysNNN name")
 	}
-	return ptyno, nil
+	return uint(n), nil
 }
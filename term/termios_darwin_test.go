@@ -0,0 +1,85 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package term
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestOpenPTYMaster tests opening the slave of a master only PTY by its /dev/ttysNNN name.
+func TestOpenPTYMaster(t *testing.T) {
+	tty, err := OpenPTYMaster()
+	if err != nil {
+		t.Fatalf("OpenPTYMaster failed: %v", err)
+	}
+	if tty.Slave != nil {
+		t.Errorf("OpenPTYMaster Slave got: %v want: <nil>", tty.Slave)
+	}
+	name, err := tty.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	if !strings.HasPrefix(name, "/dev/ttys") {
+		t.Errorf("PTSName got: %q want: /dev/ttysNNN", name)
+	}
+	if n, err := tty.PTSNumber(); err != nil || "/dev/ttys"+fmt.Sprintf("%03d", n) != name {
+		t.Errorf("PTSNumber got: %d,%v want the number of %q", n, err, name)
+	}
+	slave, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("OpenFile(%q) failed: %v", name, err)
+	}
+	defer slave.Close()
+	if !Isatty(slave) {
+		t.Errorf("Isatty(%q) got: false want: true", name)
+	}
+	if _, err := tty.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	line, err := GetLine("", slave, make([]byte, 64))
+	if err != nil {
+		t.Fatalf("GetLine failed: %v", err)
+	}
+	if got, want := string(line), "hello"; got != want {
+		t.Errorf("GetLine got: %q want: %q", got, want)
+	}
+	if err := tty.Close(); err != nil {
+		t.Errorf("Close got: %v want: <nil>", err)
+	}
+}
+
+// TestFlagValues tests the flag constants are the ones of the platform.
+func TestFlagValues(t *testing.T) {
+	for _, tst := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"IGNBRK", IGNBRK, syscall.IGNBRK}, {"BRKINT", BRKINT, syscall.BRKINT}, {"IGNPAR", IGNPAR, syscall.IGNPAR},
+		{"PARMRK", PARMRK, syscall.PARMRK}, {"INPCK", INPCK, syscall.INPCK}, {"ISTRIP", ISTRIP, syscall.ISTRIP},
+		{"INLCR", INLCR, syscall.INLCR}, {"IGNCR", IGNCR, syscall.IGNCR}, {"ICRNL", ICRNL, syscall.ICRNL},
+		{"IXON", IXON, syscall.IXON}, {"IXOFF", IXOFF, syscall.IXOFF}, {"IXANY", IXANY, syscall.IXANY},
+		{"IMAXBEL", IMAXBEL, syscall.IMAXBEL}, {"OPOST", OPOST, syscall.OPOST}, {"ONLCR", ONLCR, syscall.ONLCR},
+		{"ONOEOT", ONOEOT, syscall.ONOEOT}, {"OCRNL", OCRNL, syscall.OCRNL}, {"ONOCR", ONOCR, syscall.ONOCR},
+		{"ONLRET", ONLRET, syscall.ONLRET}, {"CSIZE", CSIZE, syscall.CSIZE}, {"CS5", CS5, syscall.CS5},
+		{"CS6", CS6, syscall.CS6}, {"CS7", CS7, syscall.CS7}, {"CS8", CS8, syscall.CS8},
+		{"CSTOPB", CSTOPB, syscall.CSTOPB}, {"CREAD", CREAD, syscall.CREAD}, {"PARENB", PARENB, syscall.PARENB},
+		{"PARODD", PARODD, syscall.PARODD}, {"HUPCL", HUPCL, syscall.HUPCL}, {"CLOCAL", CLOCAL, syscall.CLOCAL},
+		{"ECHOKE", ECHOKE, syscall.ECHOKE}, {"ECHOE", ECHOE, syscall.ECHOE}, {"ECHOK", ECHOK, syscall.ECHOK},
+		{"ECHO", ECHO, syscall.ECHO}, {"ECHONL", ECHONL, syscall.ECHONL}, {"ECHOPRT", ECHOPRT, syscall.ECHOPRT},
+		{"ECHOCTL", ECHOCTL, syscall.ECHOCTL}, {"ISIG", ISIG, syscall.ISIG}, {"ICANON", ICANON, syscall.ICANON},
+		{"IEXTEN", IEXTEN, syscall.IEXTEN}, {"EXTPROC", EXTPROC, syscall.EXTPROC},
+		{"TOSTOP", TOSTOP, syscall.TOSTOP}, {"FLUSHO", FLUSHO, syscall.FLUSHO}, {"PENDIN", PENDIN, syscall.PENDIN},
+		{"NOFLSH", NOFLSH, syscall.NOFLSH}, {"IUTF8", IUTF8, syscall.IUTF8}, {"OFILL", OFILL, syscall.OFILL},
+		{"OFDEL", OFDEL, syscall.OFDEL},
+	} {
+		if tst.got != tst.want {
+			t.Errorf("%s got: %#x want: %#x", tst.name, tst.got, tst.want)
+		}
+	}
+}
@@ -1,209 +1,182 @@
+//go:build freebsd
+
 package term
 
 import (
-	"bytes"
-	"errors"
-	"fmt"
 	"os"
-	"strconv"
-	"strings"
 	"syscall"
 	"unsafe"
 )
 
 // IOCTL terminal stuff.
 const (
-	TCGETS     = 0x5401     // TCGETS get terminal attributes
-	TCSETS     = 0x5402     // TCSETS set terminal attributes
-	TIOCGWINSZ = 0x5413     // TIOCGWINSZ used to get the terminal window size
-	TIOCSWINSZ = 0x5414     // TIOCSWINSZ used to set the terminal window size
-	TIOCGPTN   = 0x80045430 // TIOCGPTN IOCTL used to get the PTY number
-	TIOCSPTLCK = 0x40045431 // TIOCSPTLCK IOCT used to lock/unlock PTY
-	CBAUD      = 0010017    // CBAUD Serial speed settings
-	CBAUDEX    = 0010000    // CBAUDX Serial speed settings
+	TCGETS       = syscall.TIOCGETA     // TCGETS get terminal attributes
+	TCSETS       = syscall.TIOCSETA     // TCSETS set terminal attributes
+	TIOCGWINSZ   = syscall.TIOCGWINSZ   // TIOCGWINSZ used to get the terminal window size
+	TIOCSWINSZ   = syscall.TIOCSWINSZ   // TIOCSWINSZ used to set the terminal window size
+	TIOCGPTN     = syscall.TIOCGPTN     // TIOCGPTN IOCTL used to get the PTY number
+	TIOCPTMASTER = syscall.TIOCPTMASTER // TIOCPTMASTER IOCTL used to check for a PTY master
+	CBAUD        = 0010017              // CBAUD Serial speed settings
+	CBAUDEX      = 0010000              // CBAUDX Serial speed settings
+	TIOCMGET     = syscall.TIOCMGET     // TIOCMGET IOCTL used to get the modem bits
+	TIOCMBIS     = syscall.TIOCMBIS     // TIOCMBIS IOCTL used to set modem bits
+	TIOCMBIC     = syscall.TIOCMBIC     // TIOCMBIC IOCTL used to clear modem bits
+	FIONREAD     = 0x4004667f           // FIONREAD IOCTL used to get the number of bytes waiting to be read
 	// FreeBSD posix_openpt syscall.
-	OPENPT = 504
+	OPENPT = syscall.SYS_POSIX_OPENPT
+)
+
+// CRTSCTS RTS/CTS hardware flow control.
+const CRTSCTS = 0600000
+
+// Flags FreeBSD doesn't have, only here to keep the Termios methods the same on all platforms.
+const (
+	IUTF8 = 0 // IUTF8 no such flag on FreeBSD
+	OFILL = 0 // OFILL no such flag on FreeBSD
+	OFDEL = 0 // OFDEL no such flag on FreeBSD
 )
 
+// dname is the struct fiodgname_arg used with the FIODGNAME IOCTL.
+type dname struct {
+	len int32
+	buf unsafe.Pointer
+}
+
+// fiodgname IOCTL used to get the device name of a fd, _IOW('f', 120, struct fiodgname_arg).
+var fiodgname = 0x80000000 | (unsafe.Sizeof(dname{})&0x1fff)<<16 | 'f'<<8 | 120
+
+const (
+	pathDev = "/dev/"
+)
+
+// toKernel converts t to the FreeBSD kernel termios struct.
+// The FreeBSD struct has a shorter Cc array and no Line.
+func (t *Termios) toKernel() syscall.Termios {
+	kt := syscall.Termios{
+		Iflag:  t.Iflag,
+		Oflag:  t.Oflag,
+		Cflag:  t.Cflag,
+		Lflag:  t.Lflag,
+		Ispeed: t.Ispeed,
+		Ospeed: t.Ospeed,
+	}
+	copy(kt.Cc[:], t.Cc[:])
+	return kt
+}
+
+// fromKernel fills in t from the FreeBSD kernel termios struct.
+func (t *Termios) fromKernel(kt *syscall.Termios) {
+	t.Iflag = kt.Iflag
+	t.Oflag = kt.Oflag
+	t.Cflag = kt.Cflag
+	t.Lflag = kt.Lflag
+	copy(t.Cc[:], kt.Cc[:])
+	t.Ispeed = kt.Ispeed
+	t.Ospeed = kt.Ospeed
+}
+
 // Set Sets terminal t attributes on file.
 func (t *Termios) Set(file *os.File) error {
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCSETS), uintptr(unsafe.Pointer(t)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
+	kt := t.toKernel()
+	return ioctl("TIOCSETA", file.Fd(), TCSETS, uintptr(unsafe.Pointer(&kt)))
 }
 
 // Attr Gets (terminal related) attributes from file.
 func Attr(file *os.File) (Termios, error) {
 	var t Termios
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCGETS), uintptr(unsafe.Pointer(&t)))
-	if errno != 0 {
-		return t, errno
+	var kt syscall.Termios
+	if err := ioctl("TIOCGETA", file.Fd(), TCGETS, uintptr(unsafe.Pointer(&kt))); err != nil {
+		return t, err
 	}
-	t.Ispeed &= CBAUD | CBAUDEX
-	t.Ospeed &= CBAUD | CBAUDEX
+	t.fromKernel(&kt)
 	return t, nil
 }
 
-// Isatty returns true if file is a tty.
-func Isatty(file *os.File) bool {
-	_, err := Attr(file)
-	return err == nil
-}
-
-// GetPass reads password from a TTY with no echo.
-func GetPass(prompt string, f *os.File, pbuf []byte) ([]byte, error) {
-	t, err := Attr(f)
+// OpenPTY Creates a new Master/Slave PTY pair.
+func OpenPTY() (*PTY, error) {
+	pty, err := OpenPTYMaster()
 	if err != nil {
 		return nil, err
 	}
-	defer t.Set(f)
-	noecho := t
-	noecho.Lflag = noecho.Lflag &^ ECHO
-	if err := noecho.Set(f); err != nil {
-		return nil, err
-	}
-	b := make([]byte, 1, 1)
-	i := 0
-	if _, err := f.Write([]byte(prompt)); err != nil {
-		return nil, err
-	}
-	for ; i < len(pbuf); i++ {
-		if _, err := f.Read(b); err != nil {
-			b[0] = 0
-			clearbuf(pbuf[:i+1])
-		}
-		if b[0] == '\n' || b[0] == '\r' {
-			return pbuf[:i], nil
-		}
-		pbuf[i] = b[0]
-		b[0] = 0
-	}
-	clearbuf(pbuf[:i+1])
-	return nil, errors.New("ran out of bufferspace")
-}
 
-// clearbuf clears out the buffer incase we couldn't read the full password.
-func clearbuf(b []byte) {
-	for i := range b {
-		b[i] = 0
-	}
-}
-
-// Winsz Fetches the current terminal windowsize.
-// example handling changing window sizes with PTYs:
-//
-// import "os"
-// import "os/signal"
-//
-// var sig = make(chan os.Signal,2) 		// Channel to listen for UNIX SIGNALS on
-// signal.Notify(sig, syscall.SIGWINCH) // That'd be the window changing
-//
-// for {
-//	<-sig
-// 	term.Winsz(os.Stdin)			// We got signaled our terminal changed size so we read in the new value
-//  term.Setwinsz(pty.Slave) // Copy it to our virtual Terminal
-// }
-func (t *Termios) Winsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCGWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
+	sname, err := pty.PTSName()
+	if err != nil {
+		pty.Master.Close()
+		return nil, err
 	}
-	return nil
-}
 
-// Setwinsz Sets the terminal window size.
-func (t *Termios) Setwinsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCSWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
+	pty.Slave, err = os.OpenFile(sname, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		pty.Master.Close()
+		return nil, err
 	}
-	return nil
-}
+	pty.masterOnly = false
 
-type dname struct {
-	len int
-	buf unsafe.Pointer
+	return pty, nil
 }
 
-const (
-	pathDev = "/dev/"
-)
-
-// OpenPTY Creates a new Master/Slave PTY pair.
-func OpenPTY() (*PTY, error) {
-	fd, _, errno := syscall.Syscall(OPENPT, uintptr(os.O_RDWR|syscall.O_NOCTTY), uintptr(0), uintptr(0))
+// OpenPTYMaster Creates a new PTY only opening the Master, Slave is left nil.
+// The slave can be opened using the PTSName, eg. by a child process
+// that should get it as its controlling terminal.
+// The PTY is created with posix_openpt, not by opening a device. If that fails the error matches
+// ErrNoPTMX and ErrNoPTY as on the other platforms, as well as the errno.
+func OpenPTYMaster() (*PTY, error) {
+	fd, _, errno := syscall.Syscall(OPENPT, uintptr(os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC), 0, 0)
 	if errno != 0 {
-		return nil, errno
+		return nil, &ptmxError{err: errno}
 	}
+	master := os.NewFile(fd, pathDev+"ptmx")
 
-	buf := bytes.NewBufferString(pathDev)
-	buf.Grow(512)
-
-	data := make([]byte, 512)
-	copy(data, pathDev)
-	fmt.Println(data)
-	var dn dname
-	dn.buf = unsafe.Pointer(&data[len(pathDev)])
-	dn.len = 512
-	fmt.Println("In:", dn, "Data", data)
-	// Might be interesting.
-	// https://github.com/freebsd/freebsd/blob/c033b5978ec7662ff39840a19092334755c381df/sys/sys/ioccom.h
-	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(0x2000741c), uintptr(0)); errno != 0 {
-		return nil, errno
+	if err := grantpt(master); err != nil {
+		master.Close()
+		return nil, err
 	}
-	fmt.Println(Blue("Was here"))
-	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(0x80106678), uintptr(unsafe.Pointer(&dn))); errno != 0 {
-		return nil, errno
+	if err := unlockpt(master); err != nil {
+		master.Close()
+		return nil, err
 	}
-	fmt.Println("Len:", dn.len, "Data:", data)
 
-	fmt.Println(Blue("Wow.. This worked!, fd:"), fd)
+	return &PTY{Master: master, masterOnly: true}, nil
+}
 
-	return nil, nil
+// grantpt and unlockpt are no-ops on FreeBSD, the slave is ready for use as soon as the master is opened.
+// Same as libc they only check that f really is a PTY master.
+func grantpt(f *os.File) error {
+	return ioctl("TIOCPTMASTER", f.Fd(), TIOCPTMASTER, 0)
 }
 
-// Close closes the PTYs that OpenPTY created.
-func (p *PTY) Close() error {
-	slaveErr := errors.New("Slave FD nil")
-	if p.Slave != nil {
-		slaveErr = p.Slave.Close()
-	}
-	masterErr := errors.New("Master FD nil")
-	if p.Master != nil {
-		masterErr = p.Master.Close()
-	}
-	if slaveErr != nil || masterErr != nil {
-		var errs []string
-		if slaveErr != nil {
-			errs = append(errs, "Slave: "+slaveErr.Error())
-		}
-		if masterErr != nil {
-			errs = append(errs, "Master: "+masterErr.Error())
-		}
-		return errors.New(strings.Join(errs, " "))
-	}
-	return nil
+func unlockpt(f *os.File) error {
+	return ioctl("TIOCPTMASTER", f.Fd(), TIOCPTMASTER, 0)
 }
 
 // PTSName return the name of the pty.
 func (p *PTY) PTSName() (string, error) {
-	n, err := p.PTSNumber()
-	if err != nil {
+	return ttyname(p.Master)
+}
+
+// ttyname gets the device name of f using the FIODGNAME IOCTL.
+func ttyname(f *os.File) (string, error) {
+	data := make([]byte, 64)
+	dn := dname{
+		len: int32(len(data)),
+		buf: unsafe.Pointer(&data[0]),
+	}
+	if err := ioctl("FIODGNAME", f.Fd(), fiodgname, uintptr(unsafe.Pointer(&dn))); err != nil {
 		return "", err
 	}
-	return "/dev/pts/" + strconv.Itoa(int(n)), nil
+	for i, c := range data {
+		if c == 0 {
+			return pathDev + string(data[:i]), nil
+		}
+	}
+	return pathDev + string(data), nil
 }
 
 // PTSNumber return the pty number.
 func (p *PTY) PTSNumber() (uint, error) {
-	var ptyno uint
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(p.Master.Fd()), uintptr(TIOCGPTN), uintptr(unsafe.Pointer(&ptyno)))
-	if errno != 0 {
-		return 0, errno
+	var ptyno uint32
+	if err := ioctl("TIOCGPTN", p.Master.Fd(), TIOCGPTN, uintptr(unsafe.Pointer(&ptyno))); err != nil {
+		return 0, err
 	}
-	return ptyno, nil
+	return uint(ptyno), nil
 }
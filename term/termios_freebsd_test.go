@@ -351,3 +351,32 @@ func TestTattr(t *testing.T) {
 		t.Error("Tattr, should not be able to get attributes from regular file: ", nf.Name())
 	}
 }
+
+// TestFlagValues tests the flag constants are the ones of the platform.
+func TestFlagValues(t *testing.T) {
+	for _, tst := range []struct {
+		name      string
+		got, want uint64
+	}{
+		{"IGNBRK", IGNBRK, syscall.IGNBRK}, {"BRKINT", BRKINT, syscall.BRKINT}, {"IGNPAR", IGNPAR, syscall.IGNPAR},
+		{"PARMRK", PARMRK, syscall.PARMRK}, {"INPCK", INPCK, syscall.INPCK}, {"ISTRIP", ISTRIP, syscall.ISTRIP},
+		{"INLCR", INLCR, syscall.INLCR}, {"IGNCR", IGNCR, syscall.IGNCR}, {"ICRNL", ICRNL, syscall.ICRNL},
+		{"IXON", IXON, syscall.IXON}, {"IXOFF", IXOFF, syscall.IXOFF}, {"IXANY", IXANY, syscall.IXANY},
+		{"IMAXBEL", IMAXBEL, syscall.IMAXBEL}, {"OPOST", OPOST, syscall.OPOST}, {"ONLCR", ONLCR, syscall.ONLCR},
+		{"ONOEOT", ONOEOT, syscall.ONOEOT}, {"OCRNL", OCRNL, syscall.OCRNL}, {"ONOCR", ONOCR, syscall.ONOCR},
+		{"ONLRET", ONLRET, syscall.ONLRET}, {"CSIZE", CSIZE, syscall.CSIZE}, {"CS5", CS5, syscall.CS5},
+		{"CS6", CS6, syscall.CS6}, {"CS7", CS7, syscall.CS7}, {"CS8", CS8, syscall.CS8},
+		{"CSTOPB", CSTOPB, syscall.CSTOPB}, {"CREAD", CREAD, syscall.CREAD}, {"PARENB", PARENB, syscall.PARENB},
+		{"PARODD", PARODD, syscall.PARODD}, {"HUPCL", HUPCL, syscall.HUPCL}, {"CLOCAL", CLOCAL, syscall.CLOCAL},
+		{"ECHOKE", ECHOKE, syscall.ECHOKE}, {"ECHOE", ECHOE, syscall.ECHOE}, {"ECHOK", ECHOK, syscall.ECHOK},
+		{"ECHO", ECHO, syscall.ECHO}, {"ECHONL", ECHONL, syscall.ECHONL}, {"ECHOPRT", ECHOPRT, syscall.ECHOPRT},
+		{"ECHOCTL", ECHOCTL, syscall.ECHOCTL}, {"ISIG", ISIG, syscall.ISIG}, {"ICANON", ICANON, syscall.ICANON},
+		{"IEXTEN", IEXTEN, syscall.IEXTEN}, {"EXTPROC", EXTPROC, syscall.EXTPROC},
+		{"TOSTOP", TOSTOP, syscall.TOSTOP}, {"FLUSHO", FLUSHO, syscall.FLUSHO}, {"PENDIN", PENDIN, syscall.PENDIN},
+		{"NOFLSH", NOFLSH, syscall.NOFLSH},
+	} {
+		if tst.got != tst.want {
+			t.Errorf("%s got: %#x want: %#x", tst.name, tst.got, tst.want)
+		}
+	}
+}
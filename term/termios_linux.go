@@ -1,11 +1,12 @@
+//go:build linux
+
 package term
 
 import (
-	"errors"
 	"os"
 	"strconv"
-	"strings"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
@@ -13,167 +14,124 @@ import (
 const (
 	TCGETS     = 0x5401     // TCGETS get terminal attributes
 	TCSETS     = 0x5402     // TCSETS set terminal attributes
+	TCSETSW    = 0x5403     // TCSETSW set terminal attributes after draining output
+	TCSETSF    = 0x5404     // TCSETSF set terminal attributes after draining output and flushing input
 	TIOCGWINSZ = 0x5413     // TIOCGWINSZ used to get the terminal window size
 	TIOCSWINSZ = 0x5414     // TIOCSWINSZ used to set the terminal window size
 	TIOCGPTN   = 0x80045430 // TIOCGPTN IOCTL used to get the PTY number
 	TIOCSPTLCK = 0x40045431 // TIOCSPTLCK IOCT used to lock/unlock PTY
 	CBAUD      = 0010017    // CBAUD Serial speed settings
 	CBAUDEX    = 0010000    // CBAUDX Serial speed settings
+	TCFLSH     = 0x540B     // TCFLSH IOCTL used to flush the terminal queues
+	TCSBRK     = 0x5409     // TCSBRK IOCTL used to drain output or send a break
+	TIOCSBRK   = 0x5427     // TIOCSBRK IOCTL used to turn on the break
+	TIOCCBRK   = 0x5428     // TIOCCBRK IOCTL used to turn off the break
+	TIOCMGET   = 0x5415     // TIOCMGET IOCTL used to get the modem bits
+	TIOCMBIS   = 0x5416     // TIOCMBIS IOCTL used to set modem bits
+	TIOCMBIC   = 0x5417     // TIOCMBIC IOCTL used to clear modem bits
+	FIONREAD   = 0x541B     // FIONREAD IOCTL used to get the number of bytes waiting to be read
 )
 
+// CRTSCTS RTS/CTS hardware flow control.
+const CRTSCTS = 020000000000
+
 // Set Sets terminal t attributes on file.
 func (t *Termios) Set(file *os.File) error {
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCSETS), uintptr(unsafe.Pointer(t)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
+	return ioctl("TCSETS", file.Fd(), TCSETS, uintptr(unsafe.Pointer(t)))
 }
 
-// Attr Gets (terminal related) attributes from file.
-func Attr(file *os.File) (Termios, error) {
-	var t Termios
-	fd := file.Fd()
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(TCGETS), uintptr(unsafe.Pointer(&t)))
-	if errno != 0 {
-		return t, errno
-	}
-	t.Ispeed &= CBAUD | CBAUDEX
-	t.Ospeed &= CBAUD | CBAUDEX
-	return t, nil
+// SetDrain Sets terminal t attributes on file once all output written to it has been transmitted.
+func (t *Termios) SetDrain(file *os.File) error {
+	return ioctl("TCSETSW", file.Fd(), TCSETSW, uintptr(unsafe.Pointer(t)))
 }
 
-// Isatty returns true if file is a tty.
-func Isatty(file *os.File) bool {
-	_, err := Attr(file)
-	return err == nil
+// SetFlush Sets terminal t attributes on file once all output written to it has been transmitted,
+// input received but not read is discarded.
+func (t *Termios) SetFlush(file *os.File) error {
+	return ioctl("TCSETSF", file.Fd(), TCSETSF, uintptr(unsafe.Pointer(t)))
 }
 
-// GetPass reads password from a TTY with no echo.
-func GetPass(prompt string, f *os.File, pbuf []byte) ([]byte, error) {
-	t, err := Attr(f)
-	if err != nil {
-		return nil, err
-	}
-	defer t.Set(f)
-	noecho := t
-	noecho.Lflag = noecho.Lflag &^ ECHO
-	if err := noecho.Set(f); err != nil {
-		return nil, err
-	}
-	b := make([]byte, 1, 1)
-	i := 0
-	if _, err := f.Write([]byte(prompt)); err != nil {
-		return nil, err
+// Attr Gets (terminal related) attributes from file.
+func Attr(file *os.File) (Termios, error) {
+	var t Termios
+	if err := ioctl("TCGETS", file.Fd(), TCGETS, uintptr(unsafe.Pointer(&t))); err != nil {
+		return t, err
 	}
-	for ; i < len(pbuf); i++ {
-		if _, err := f.Read(b); err != nil {
-			b[0] = 0
-			clearbuf(pbuf[:i+1])
-		}
-		if b[0] == '\n' || b[0] == '\r' {
-			return pbuf[:i], nil
-		}
-		pbuf[i] = b[0]
-		b[0] = 0
+	// The kernel termios struct has no speeds, same as libc they're picked up from Cflag.
+	t.Ospeed = t.Cflag & (CBAUD | CBAUDEX)
+	t.Ispeed = t.Cflag & CIBAUD >> IBSHIFT
+	if t.Ispeed == 0 {
+		t.Ispeed = t.Ospeed
 	}
-	clearbuf(pbuf[:i+1])
-	return nil, errors.New("ran out of bufferspace")
+	return t, nil
 }
 
-// clearbuf clears out the buffer incase we couldn't read the full password.
-func clearbuf(b []byte) {
-	for i := range b {
-		b[i] = 0
-	}
+// tcflush discards the which queue of f, TCFLSH takes the same values as the Flush queues.
+func tcflush(f *os.File, which int) error {
+	return ioctl("TCFLSH", f.Fd(), TCFLSH, uintptr(which))
 }
 
-// Winsz Fetches the current terminal windowsize.
-// example handling changing window sizes with PTYs:
-//
-// import "os"
-// import "os/signal"
-//
-// var sig = make(chan os.Signal,2) 		// Channel to listen for UNIX SIGNALS on
-// signal.Notify(sig, syscall.SIGWINCH) // That'd be the window changing
-//
-// for {
-//	<-sig
-// 	term.Winsz(os.Stdin)			// We got signaled our terminal changed size so we read in the new value
-//  term.Setwinsz(pty.Slave) // Copy it to our virtual Terminal
-// }
-func (t *Termios) Winsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCGWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
-	}
-	return nil
+// tcdrain waits for the output of f to be transmitted, TCSBRK with a nonzero argument only drains.
+func tcdrain(f *os.File) error {
+	return ioctl("TCSBRK", f.Fd(), TCSBRK, 1)
 }
 
-// Setwinsz Sets the terminal window size.
-func (t *Termios) Setwinsz(file *os.File) error {
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(file.Fd()), uintptr(TIOCSWINSZ), uintptr(unsafe.Pointer(&t.Wz)))
-	if errno != 0 {
-		return errno
+// setBreak turns the break on f on or off.
+func setBreak(f *os.File, on bool) error {
+	if on {
+		return ioctl("TIOCSBRK", f.Fd(), TIOCSBRK, 0)
 	}
-	return nil
+	return ioctl("TIOCCBRK", f.Fd(), TIOCCBRK, 0)
 }
 
 // OpenPTY Creates a new Master/Slave PTY pair.
 func OpenPTY() (*PTY, error) {
-	// Opening ptmx gives you the FD of a brand new PTY
-	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	pty, err := OpenPTYMaster()
 	if err != nil {
 		return nil, err
 	}
 
-	// unlock pty slave
-	var unlock int // 0 => Unlock
-	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(master.Fd()), uintptr(TIOCSPTLCK), uintptr(unsafe.Pointer(&unlock))); errno != 0 {
-		master.Close()
-		return nil, errno
-	}
-
 	// get path of pts slave
-	pty := &PTY{Master: master}
 	slaveStr, err := pty.PTSName()
 	if err != nil {
-		master.Close()
+		pty.Master.Close()
 		return nil, err
 	}
 
 	// open pty slave
 	pty.Slave, err = os.OpenFile(slaveStr, os.O_RDWR|syscall.O_NOCTTY, 0)
 	if err != nil {
-		master.Close()
+		pty.Master.Close()
 		return nil, err
 	}
+	pty.masterOnly = false
 
 	return pty, nil
 }
 
-// Close closes the PTYs that OpenPTY created.
-func (p *PTY) Close() error {
-	slaveErr := errors.New("Slave FD nil")
-	if p.Slave != nil {
-		slaveErr = p.Slave.Close()
-	}
-	masterErr := errors.New("Master FD nil")
-	if p.Master != nil {
-		masterErr = p.Master.Close()
+// OpenPTYMaster Creates a new PTY only opening the Master, Slave is left nil.
+// The slave is unlocked so it can be opened using the PTSName, eg. by a child process
+// that should get it as its controlling terminal.
+func OpenPTYMaster() (*PTY, error) {
+	// Opening ptmx gives you the FD of a brand new PTY
+	master, err := openPTMX("/dev/ptmx", "/dev/pts/ptmx")
+	if err != nil {
+		return nil, err
 	}
-	if slaveErr != nil || masterErr != nil {
-		var errs []string
-		if slaveErr != nil {
-			errs = append(errs, "Slave: "+slaveErr.Error())
-		}
-		if masterErr != nil {
-			errs = append(errs, "Master: "+masterErr.Error())
-		}
-		return errors.New(strings.Join(errs, " "))
+
+	// unlock pty slave
+	var unlock int32 // 0 => Unlock
+	if err := ioctl("TIOCSPTLCK", master.Fd(), TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, err
 	}
-	return nil
+
+	return &PTY{Master: master, masterOnly: true}, nil
+}
+
+// ttyname resolves the path of f through its /proc/self/fd link.
+func ttyname(f *os.File) (string, error) {
+	return os.Readlink("/proc/self/fd/" + strconv.Itoa(int(f.Fd())))
 }
 
 // PTSName return the name of the pty.
@@ -187,10 +145,23 @@ func (p *PTY) PTSName() (string, error) {
 
 // PTSNumber return the pty number.
 func (p *PTY) PTSNumber() (uint, error) {
-	var ptyno uint
-	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(p.Master.Fd()), uintptr(TIOCGPTN), uintptr(unsafe.Pointer(&ptyno)))
-	if errno != 0 {
-		return 0, errno
+	var ptyno uint32
+	if err := ioctl("TIOCGPTN", p.Master.Fd(), TIOCGPTN, uintptr(unsafe.Pointer(&ptyno))); err != nil {
+		return 0, err
 	}
-	return ptyno, nil
+	return uint(ptyno), nil
+}
+
+// poll waits up to timeout for the events of fds, a negative timeout waits for as long as it takes.
+// It's done with ppoll as not all architectures have poll.
+func poll(fds []pollFd, timeout time.Duration) error {
+	var ts *syscall.Timespec
+	if timeout >= 0 {
+		t := syscall.NsecToTimespec(int64(timeout))
+		ts = &t
+	}
+	if _, _, e := syscall.Syscall6(syscall.SYS_PPOLL, uintptr(unsafe.Pointer(&fds[0])), uintptr(len(fds)), uintptr(unsafe.Pointer(ts)), 0, 0, 0); e != 0 {
+		return e
+	}
+	return nil
 }
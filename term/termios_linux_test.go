@@ -6,13 +6,16 @@ package term
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"math/rand"
 	"os"
+	"os/signal"
 	"strconv"
 	"sync"
 	"syscall"
 	"testing"
+	"time"
 )
 
 var pty *PTY
@@ -132,6 +135,16 @@ func TestGetPass(t *testing.T) {
 	if string(pass) != tststring[:len(tststring)-1] {
 		t.Errorf("GetPass got: %q want: %q", pass, tststring)
 	}
+	// The reader might not have picked up the prompt from the master yet.
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		nr := readbuffer.Len()
+		mu.Unlock()
+		if nr >= len("TestGetPass:") {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
 	mu.Lock()
 	if readbuffer.String() != "TestGetPass:" {
 		t.Errorf("GetPass got: %q want: %q", readbuffer.String(), "TestGetPass:")
@@ -203,6 +216,101 @@ func TestGetChar(t *testing.T) {
 	}
 }
 
+// TestReadWithTimeout tests reads giving up and the attributes being restored.
+func TestReadWithTimeout(t *testing.T) {
+	tty, err := OpenPTYRaw()
+	if err != nil {
+		t.Fatalf("OpenPTYRaw failed: %v", err)
+	}
+	defer tty.Close()
+	want, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	b := make([]byte, 16)
+	start := time.Now()
+	if n, err := ReadWithTimeout(tty.Slave, b, 200*time.Millisecond); err != ErrTimeout || n != 0 {
+		t.Errorf("ReadWithTimeout with no input got: %d,%v want: 0,%v", n, err, ErrTimeout)
+	}
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("ReadWithTimeout gave up after: %v want: 200ms", d)
+	}
+	if got, err := Attr(tty.Slave); err != nil || got != want {
+		t.Errorf("ReadWithTimeout left attributes: %v want: %v", got, want)
+	}
+	tty.Write([]byte("abc"))
+	if n, err := ReadWithTimeout(tty.Slave, b, time.Second); err != nil || string(b[:n]) != "abc" {
+		t.Errorf("ReadWithTimeout got: %q,%v want: %q,<nil>", b[:n], err, "abc")
+	}
+	tty.Write([]byte("blocking"))
+	if n, err := ReadWithTimeout(tty.Slave, b, 0); err != nil || string(b[:n]) != "blocking" {
+		t.Errorf("ReadWithTimeout(0) got: %q,%v want: %q,<nil>", b[:n], err, "blocking")
+	}
+	// In canonical mode the wait is for a complete line.
+	cooked, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer cooked.Close()
+	readMaster(cooked)
+	want, err = Attr(cooked.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	cooked.Write([]byte("partial"))
+	start = time.Now()
+	if n, err := ReadWithTimeout(cooked.Slave, b, 300*time.Millisecond); err != ErrTimeout || n != 0 {
+		t.Errorf("ReadWithTimeout cooked with no line got: %d,%v want: 0,%v", n, err, ErrTimeout)
+	}
+	if d := time.Since(start); d < 300*time.Millisecond || d > 2*time.Second {
+		t.Errorf("ReadWithTimeout cooked gave up after: %v want: 300ms", d)
+	}
+	cooked.Write([]byte(" line\n"))
+	if n, err := ReadWithTimeout(cooked.Slave, b, time.Second); err != nil || string(b[:n]) != "partial line\n" {
+		t.Errorf("ReadWithTimeout cooked got: %q,%v want: %q,<nil>", b[:n], err, "partial line\n")
+	}
+	if got, err := Attr(cooked.Slave); err != nil || got != want {
+		t.Errorf("ReadWithTimeout cooked left attributes: %v want: %v", got, want)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	if _, err := ReadWithTimeout(r, b, 100*time.Millisecond); err != ErrTimeout {
+		t.Errorf("ReadWithTimeout of pipe got: %v want: %v", err, ErrTimeout)
+	}
+	w.Close()
+	if _, err := ReadWithTimeout(r, b, time.Second); err != io.EOF {
+		t.Errorf("ReadWithTimeout of closed pipe got: %v want: %v", err, io.EOF)
+	}
+}
+
+// TestConfigureOptions tests applying options to a terminal.
+func TestConfigureOptions(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := Configure(tty.Slave, WithRaw(), WithVMin(0), WithSpeed(115200)); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	got, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if got.Lflag&(ICANON|ECHO) != 0 || got.VMin() != 0 || got.OutputSpeed() != 115200 {
+		t.Errorf("Configure got Lflag: %#o VMin: %d speed: %d want raw, 0, 115200", got.Lflag, got.VMin(), got.OutputSpeed())
+	}
+	if err := Configure(tty.Slave, WithEcho(true), WithSpeed(9601)); err == nil {
+		t.Errorf("Configure with invalid speed got: <nil> want: not a supported speed")
+	}
+	if now, err := Attr(tty.Slave); err != nil || now != got {
+		t.Errorf("Configure with invalid option changed attributes: %v want: %v", now, got)
+	}
+}
+
 // TestPTSName Gets name and tests if it's really a char device.
 func TestPTSName(t *testing.T) {
 	name, err := pty.PTSName()
@@ -351,3 +459,958 @@ func TestTattr(t *testing.T) {
 		t.Error("Tattr, should not be able to get attributes from regular file: ", nf.Name())
 	}
 }
+
+// TestMakeRaw tests setting a terminal to raw mode and restoring it.
+func TestMakeRaw(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	backup, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	restore, err := MakeRaw(tty.Slave)
+	if err != nil {
+		t.Fatalf("MakeRaw(tty.Slave) failed: %v", err)
+	}
+	raw, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	if err := testraw(raw, "TestMakeRaw"); err != nil {
+		t.Errorf("TestMakeRaw failed: %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restore() failed: %v", err)
+	}
+	restored, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	if restored != backup {
+		t.Errorf("restore() got: %v want: %v", restored, backup)
+	}
+	// Second call should be a no-op.
+	raw.Set(tty.Slave)
+	if err := restore(); err != nil {
+		t.Errorf("restore() second call failed: %v", err)
+	}
+	if got, _ := Attr(tty.Slave); got != raw {
+		t.Errorf("restore() second call should be a no-op, got: %v want: %v", got, raw)
+	}
+	f, err := donormfile("TestMakeRaw")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestMakeRaw\") failed: %v", err)
+	}
+	defer f.Close()
+	if _, err := MakeRaw(f); err == nil {
+		t.Errorf("MakeRaw(%q) got: <nil> want: not a tty error", f.Name())
+	}
+}
+
+// TestSetCbreak tests toggling a terminal between cbreak and cooked mode.
+func TestSetCbreak(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	if err := tr.SetCbreak(tty.Slave); err != nil {
+		t.Fatalf("SetCbreak(tty.Slave) failed: %v", err)
+	}
+	got, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr(tty.Slave) failed: %v", err)
+	}
+	if got.Lflag&(ICANON|ECHO) != 0 {
+		t.Errorf("SetCbreak failed clearing Lflag, got: %d want: 0", got.Lflag&(ICANON|ECHO))
+	}
+	if got.Lflag&ISIG != ISIG || got.Oflag&OPOST != OPOST {
+		t.Errorf("SetCbreak should keep ISIG and OPOST, got Lflag: %d Oflag: %d", got.Lflag, got.Oflag)
+	}
+	if !(got.Cc[VMIN] == 1 && got.Cc[VTIME] == 0) {
+		t.Errorf("SetCbreak failed setting Cc, got VMIN: %d VTIME: %d want VMIN: 1 VTIME: 0", got.Cc[VMIN], got.Cc[VTIME])
+	}
+	if got != tr {
+		t.Errorf("SetCbreak applied: %v want: %v", got, tr)
+	}
+	if err := tr.SetCooked(tty.Slave); err != nil {
+		t.Fatalf("SetCooked(tty.Slave) failed: %v", err)
+	}
+	if got, _ = Attr(tty.Slave); got.Lflag&(ICANON|ECHO|ISIG) != ICANON|ECHO|ISIG {
+		t.Errorf("SetCooked failed setting Lflag, got: %d want: %d", got.Lflag, ICANON|ECHO|ISIG)
+	}
+}
+
+// TestReset tests resetting a terminal left in a weird state.
+func TestReset(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tr.Raw()
+	tr.Iflag |= IGNCR | IUTF8
+	tr.Lflag |= TOSTOP
+	tr.Cc[VINTR] = 0x01
+	tr.Cc[VMIN] = 0
+	tr.Cc[VTIME] = 5
+	if err := tr.Set(tty.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := Reset(tty.Slave); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	got, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if want := uint32(ICANON | ECHO | ISIG); got.Lflag&want != want || got.Lflag&TOSTOP != 0 {
+		t.Errorf("Reset Lflag got: %o want: %o set and TOSTOP cleared", got.Lflag, want)
+	}
+	if got.Iflag&ICRNL == 0 || got.Iflag&IGNCR != 0 || got.Iflag&IUTF8 == 0 {
+		t.Errorf("Reset Iflag got: %o want: ICRNL IUTF8 set IGNCR cleared", got.Iflag)
+	}
+	if got.Oflag&OPOST == 0 {
+		t.Errorf("Reset Oflag got: %o want: OPOST set", got.Oflag)
+	}
+	if got.Cc[VINTR] != 0x03 || got.Cc[VMIN] != 1 || got.Cc[VTIME] != 0 {
+		t.Errorf("Reset Cc got VINTR: %#x VMIN: %d VTIME: %d want VINTR: 0x3 VMIN: 1 VTIME: 0", got.Cc[VINTR], got.Cc[VMIN], got.Cc[VTIME])
+	}
+	if got.Ospeed != tr.Ospeed || got.Cflag&CSIZE != tr.Cflag&CSIZE {
+		t.Errorf("Reset changed speed or character size got: %v want: %v", got, tr)
+	}
+}
+
+// TestCopyAttr tests copying the attributes between terminals.
+func TestCopyAttr(t *testing.T) {
+	src, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer src.Close()
+	dst, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer dst.Close()
+	tr, err := Attr(src.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tr.Raw()
+	tr.Cc[VINTR] = 0x01
+	if err := tr.SetSpeed(9600); err != nil {
+		t.Fatalf("SetSpeed failed: %v", err)
+	}
+	if err := tr.Set(src.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	want, _ := Attr(src.Slave)
+	if err := CopyAttr(dst.Slave, src.Slave); err != nil {
+		t.Fatalf("CopyAttr failed: %v", err)
+	}
+	if got, err := Attr(dst.Slave); err != nil || got != want {
+		t.Errorf("CopyAttr got: %v differing in %q", got, want.Diff(got))
+	}
+	nf, err := donormfile("TestCopyAttr")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestCopyAttr\") failed: %v", err)
+	}
+	defer nf.Close()
+	if err := CopyAttr(dst.Slave, nf); err == nil {
+		t.Errorf("CopyAttr from normal file got: <nil> want: error")
+	}
+	if err := CopyAttr(nf, src.Slave); err == nil {
+		t.Errorf("CopyAttr to normal file got: <nil> want: error")
+	}
+}
+
+// TestCloseError tests the errors returned from Close.
+func TestCloseError(t *testing.T) {
+	var nilPTY *PTY
+	if err := nilPTY.Close(); err != ErrNoPTY {
+		t.Errorf("Close() of nil PTY got: %v want: %v", err, ErrNoPTY)
+	}
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	tty.Slave.Close()
+	err = tty.Close()
+	var cerr *CloseError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Close() got: %v want: *CloseError", err)
+	}
+	if cerr.Slave == nil || cerr.Master != nil {
+		t.Errorf("Close() got Slave: %v Master: %v want Slave: <error> Master: <nil>", cerr.Slave, cerr.Master)
+	}
+	if !errors.Is(err, os.ErrClosed) {
+		t.Errorf("errors.Is(%v, os.ErrClosed) got: false want: true", err)
+	}
+	if tty, err = OpenPTY(); err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	slave := tty.Slave
+	defer slave.Close()
+	tty.Slave = nil
+	err = tty.Close()
+	if !errors.Is(err, ErrNilSlave) || errors.Is(err, ErrNilMaster) {
+		t.Errorf("Close() got: %v want: %v", err, ErrNilSlave)
+	}
+}
+
+// TestCloseSides tests closing the sides of a PTY one at a time.
+func TestCloseSides(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	slave := tty.Slave
+	if err := tty.CloseSlave(); err != nil {
+		t.Fatalf("CloseSlave() failed: %v", err)
+	}
+	if tty.Slave != nil {
+		t.Errorf("CloseSlave() left Slave: %v want: <nil>", tty.Slave)
+	}
+	if _, err := slave.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write to closed Slave got: %v want: %v", err, os.ErrClosed)
+	}
+	if err := tty.CloseSlave(); err != ErrNilSlave {
+		t.Errorf("CloseSlave() twice got: %v want: %v", err, ErrNilSlave)
+	}
+	if err := tty.Close(); err != nil {
+		t.Errorf("Close() after CloseSlave got: %v want: <nil>", err)
+	}
+
+	if tty, err = OpenPTY(); err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	if err := tty.CloseMaster(); err != nil {
+		t.Fatalf("CloseMaster() failed: %v", err)
+	}
+	if tty.Master != nil {
+		t.Errorf("CloseMaster() left Master: %v want: <nil>", tty.Master)
+	}
+	if err := tty.CloseMaster(); err != ErrNilMaster {
+		t.Errorf("CloseMaster() twice got: %v want: %v", err, ErrNilMaster)
+	}
+	if err := tty.Close(); err != nil {
+		t.Errorf("Close() after CloseMaster got: %v want: <nil>", err)
+	}
+
+	var nilPTY *PTY
+	if err := nilPTY.CloseSlave(); err != ErrNoPTY {
+		t.Errorf("CloseSlave() of nil PTY got: %v want: %v", err, ErrNoPTY)
+	}
+	if err := nilPTY.CloseMaster(); err != ErrNoPTY {
+		t.Errorf("CloseMaster() of nil PTY got: %v want: %v", err, ErrNoPTY)
+	}
+}
+
+// TestIoctlError tests that failing IOCTLs report the operation.
+func TestIoctlError(t *testing.T) {
+	nf, err := donormfile("TestIoctlError")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestIoctlError\") failed: %v", err)
+	}
+	defer nf.Close()
+	var tr Termios
+	_, attrErr := Attr(nf)
+	tsts := []struct {
+		op  string
+		err error
+	}{
+		{"TCGETS", attrErr},
+		{"TCSETS", tr.Set(nf)},
+		{"TIOCGWINSZ", tr.Winsz(nf)},
+		{"TIOCSWINSZ", tr.Setwinsz(nf)},
+	}
+	for _, tst := range tsts {
+		var ierr *IoctlError
+		if !errors.As(tst.err, &ierr) {
+			t.Errorf("%s got: %v want: *IoctlError", tst.op, tst.err)
+			continue
+		}
+		if ierr.Op != tst.op {
+			t.Errorf("IoctlError.Op got: %q want: %q", ierr.Op, tst.op)
+		}
+		if !errors.Is(tst.err, syscall.ENOTTY) {
+			t.Errorf("errors.Is(%v, syscall.ENOTTY) got: false want: true", tst.err)
+		}
+		if want := "ioctl " + tst.op + ": " + syscall.ENOTTY.Error(); tst.err.Error() != want {
+			t.Errorf("IoctlError.Error() got: %q want: %q", tst.err.Error(), want)
+		}
+	}
+}
+
+// TestSetEcho tests toggling echo on and off.
+func TestSetEcho(t *testing.T) {
+	var tr Termios
+	tr.SetEcho(true)
+	if tr.Lflag&ECHO == 0 {
+		t.Errorf("SetEcho(true) got Lflag: %d want ECHO set", tr.Lflag)
+	}
+	tr.SetEcho(false)
+	if tr.Lflag&ECHO != 0 {
+		t.Errorf("SetEcho(false) got Lflag: %d want ECHO cleared", tr.Lflag)
+	}
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	for _, on := range []bool{false, true} {
+		if err := SetEchoFile(tty.Slave, on); err != nil {
+			t.Fatalf("SetEchoFile(tty.Slave, %t) failed: %v", on, err)
+		}
+		got, err := Attr(tty.Slave)
+		if err != nil {
+			t.Fatalf("Attr(tty.Slave) failed: %v", err)
+		}
+		if (got.Lflag&ECHO != 0) != on {
+			t.Errorf("SetEchoFile(tty.Slave, %t) got Lflag: %d", on, got.Lflag)
+		}
+	}
+	nf, err := donormfile("TestSetEcho")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestSetEcho\") failed: %v", err)
+	}
+	defer nf.Close()
+	if err := SetEchoFile(nf, false); err == nil {
+		t.Errorf("SetEchoFile(%q, false) got: <nil> want: not a tty error", nf.Name())
+	}
+}
+
+// TestSetOutputProcessing tests the NL to CR-NL mapping done by the output processing.
+func TestSetOutputProcessing(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	tr, err := Attr(tty.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tsts := []struct {
+		opost, onlcr bool
+		want         string
+	}{
+		{true, true, "a\r\n"},
+		{true, false, "a\n"},
+		{false, true, "a\n"},
+	}
+	b := make([]byte, 16)
+	for _, tst := range tsts {
+		tr.SetOutputProcessing(tst.opost)
+		tr.SetNLCR(tst.onlcr)
+		if (tr.Oflag&OPOST != 0) != tst.opost || (tr.Oflag&ONLCR != 0) != tst.onlcr {
+			t.Errorf("SetOutputProcessing(%t) SetNLCR(%t) got Oflag: %o", tst.opost, tst.onlcr, tr.Oflag)
+		}
+		if err := tr.Set(tty.Slave); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		tty.Slave.Write([]byte("a\n"))
+		if n, err := tty.Master.Read(b); err != nil || string(b[:n]) != tst.want {
+			t.Errorf("opost: %t onlcr: %t output got: %q,%v want: %q,<nil>", tst.opost, tst.onlcr, b[:n], err, tst.want)
+		}
+	}
+}
+
+// TestFlowControl tests turning software and hardware flow control on and off.
+func TestFlowControl(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	tios, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	for _, tst := range []struct {
+		soft, hard bool
+	}{{true, false}, {false, true}, {true, true}, {false, false}} {
+		tios.SetSoftwareFlow(tst.soft)
+		tios.SetHardwareFlow(tst.hard)
+		if err := tios.Set(p.Slave); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		soft, hard, err := FlowControl(p.Slave)
+		if err != nil {
+			t.Fatalf("FlowControl failed: %v", err)
+		}
+		if soft != tst.soft || hard != tst.hard {
+			t.Errorf("FlowControl got: %t,%t want: %t,%t", soft, hard, tst.soft, tst.hard)
+		}
+	}
+}
+
+// TestSetDrainFlush tests SetNow, SetDrain and SetFlush, and that SetFlush drops the pending input.
+func TestSetDrainFlush(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	tios, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	for _, tst := range []struct {
+		name string
+		set  func(*os.File) error
+		echo bool
+	}{
+		{"SetNow", tios.SetNow, false},
+		{"SetDrain", tios.SetDrain, true},
+		{"SetFlush", tios.SetFlush, false},
+	} {
+		tios.SetEcho(tst.echo)
+		if err := tst.set(p.Slave); err != nil {
+			t.Fatalf("%s failed: %v", tst.name, err)
+		}
+		got, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		if got.Lflag&ECHO != 0 != tst.echo {
+			t.Errorf("%s ECHO got: %t want: %t", tst.name, got.Lflag&ECHO != 0, tst.echo)
+		}
+	}
+	if _, err := p.Master.Write([]byte("stale\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// Give the line discipline time to queue the input.
+	time.Sleep(50 * time.Millisecond)
+	if err := tios.SetFlush(p.Slave); err != nil {
+		t.Fatalf("SetFlush failed: %v", err)
+	}
+	if _, err := p.Master.Write([]byte("fresh\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	line, err := GetLine("", p.Slave, make([]byte, 64))
+	if err != nil {
+		t.Fatalf("GetLine failed: %v", err)
+	}
+	if got, want := string(line), "fresh"; got != want {
+		t.Errorf("SetFlush input got: %q want: %q", got, want)
+	}
+}
+
+// TestSetVMinVTime tests setting VMIN and VTIME and reading them back from the pty.
+func TestSetVMinVTime(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	tios, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	tios.Lflag &^= ICANON
+	tios.SetVMin(0)
+	tios.SetVTime(5)
+	if err := tios.Set(p.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	got, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if got.VMin() != 0 || got.VTime() != 5 {
+		t.Errorf("VMin,VTime got: %d,%d want: 0,5", got.VMin(), got.VTime())
+	}
+	if got.Cc[VMIN] != got.VMin() || got.Cc[VTIME] != got.VTime() {
+		t.Errorf("Cc[VMIN],Cc[VTIME] got: %d,%d want: %d,%d", got.Cc[VMIN], got.Cc[VTIME], got.VMin(), got.VTime())
+	}
+}
+
+// TestControlChar tests setting a control character by name and reading it back from the pty.
+func TestControlChar(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	readMaster(p)
+	tios, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if err := tios.SetControlChar(CharErase, 'X'); err != nil {
+		t.Fatalf("SetControlChar failed: %v", err)
+	}
+	if err := tios.Set(p.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	got, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if c, err := got.ControlChar(CharErase); err != nil || c != 'X' || got.Cc[VERASE] != 'X' {
+		t.Errorf("ControlChar(CharErase) got: %q,%v want: %q,<nil>", c, err, 'X')
+	}
+	// The line discipline should now erase with the remapped char.
+	if _, err := p.Master.Write([]byte("abXc\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	b := make([]byte, 64)
+	nr, err := p.Slave.Read(b)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if res, want := string(b[:nr]), "ac\n"; res != want {
+		t.Errorf("Read got: %q want: %q", res, want)
+	}
+	for name, idx := range map[ControlCharName]int{CharEOF: VEOF, CharIntr: VINTR, CharQuit: VQUIT, CharKill: VKILL, CharSusp: VSUSP,
+		CharEOL: VEOL, CharEOL2: VEOL2, CharWerase: VWERASE, CharReprint: VREPRINT, CharLnext: VLNEXT,
+		CharDiscard: VDISCARD, CharStart: VSTART, CharStop: VSTOP} {
+		if c, err := got.ControlChar(name); err != nil || c != got.Cc[idx] {
+			t.Errorf("ControlChar(%d) got: %d,%v want: %d,<nil>", name, c, err, got.Cc[idx])
+		}
+	}
+	// And the extended ones, erasing a word and quoting the next char.
+	if err := got.SetControlChar(CharWerase, 'W'); err != nil {
+		t.Fatalf("SetControlChar failed: %v", err)
+	}
+	if err := got.SetControlChar(CharLnext, 'L'); err != nil {
+		t.Fatalf("SetControlChar failed: %v", err)
+	}
+	if err := got.Set(p.Slave); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := p.Master.Write([]byte("ab cdW LWe\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	nr, err = p.Slave.Read(b)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if res, want := string(b[:nr]), "ab  We\n"; res != want {
+		t.Errorf("Read with CharWerase and CharLnext got: %q want: %q", res, want)
+	}
+}
+
+// TestAvailable tests the number of bytes waiting to be read on both ends of the pty and on a pipe.
+func TestAvailable(t *testing.T) {
+	p, err := OpenPTYRaw()
+	if err != nil {
+		t.Fatalf("OpenPTYRaw failed: %v", err)
+	}
+	defer p.Close()
+	// The bytes go through the PTY buffers asynchronously, wait for all of them.
+	waitAvailable := func(f *os.File, want int) {
+		var got int
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if got, err = Available(f); err != nil || got == want {
+				break
+			}
+		}
+		if err != nil || got != want {
+			t.Errorf("Available(%s) got: %d,%v want: %d,<nil>", f.Name(), got, err, want)
+		}
+	}
+	waitAvailable(p.Slave, 0)
+	if _, err := p.Master.Write([]byte("hello")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	waitAvailable(p.Slave, 5)
+	b := make([]byte, 5)
+	if _, err := io.ReadFull(p.Slave, b); err != nil {
+		t.Fatalf("ReadFull failed: %v", err)
+	}
+	waitAvailable(p.Slave, 0)
+	if _, err := p.Slave.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	waitAvailable(p.Master, 3)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.Write([]byte("pipe"))
+	waitAvailable(r, 4)
+}
+
+// TestRestoreOnSignal tests the attributes being restored on the signal, and left alone once cleaned up.
+func TestRestoreOnSignal(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	want, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	// Caught here as well so the raised again signal doesn't kill the test.
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGUSR1)
+	defer signal.Stop(sig)
+	wait := func() {
+		select {
+		case <-sig:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("SIGUSR1 not delivered")
+		}
+	}
+	raw := want
+	raw.Raw()
+	for _, stop := range []bool{false, true} {
+		cleanup := RestoreOnSignal(p.Slave, syscall.SIGUSR1)
+		if err := raw.Set(p.Slave); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if stop {
+			cleanup()
+		}
+		syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+		wait()
+		if !stop {
+			// Once more when raised again by the handler.
+			wait()
+		}
+		cleanup()
+		got, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		if stop && got != raw {
+			t.Errorf("RestoreOnSignal after cleanup got: %v want: %v", got, raw)
+		}
+		if !stop && got != want {
+			t.Errorf("RestoreOnSignal got: %v want: %v", got, want)
+		}
+	}
+}
+
+// TestGuard tests the attributes being restored by Guard both on return and on a panic.
+func TestGuard(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	want, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	raw := func(crash bool) {
+		defer Guard(p.Slave)()
+		tios := want
+		tios.Raw()
+		if err := tios.Set(p.Slave); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if crash {
+			panic("crash")
+		}
+	}
+	for _, crash := range []bool{false, true} {
+		r := func() (r any) {
+			defer func() { r = recover() }()
+			raw(crash)
+			return nil
+		}()
+		if crash && r != "crash" {
+			t.Errorf("Guard panic got: %v want: crash", r)
+		}
+		if !crash && r != nil {
+			t.Errorf("Guard panic got: %v want: <nil>", r)
+		}
+		got, err := Attr(p.Slave)
+		if err != nil {
+			t.Fatalf("Attr failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Guard crash: %t got: %v want: %v", crash, got, want)
+		}
+	}
+}
+
+// TestIsattyFd checks IsattyFd on the fd of a standard file, a tty and a dup of the tty.
+func TestIsattyFd(t *testing.T) {
+	f, err := donormfile("TestIsattyFd")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestIsattyFd\") failed: %v", err)
+	}
+	defer f.Close()
+	if IsattyFd(f.Fd()) {
+		t.Errorf("IsattyFd for normal file %v got: true want: false", f)
+	}
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	if !IsattyFd(p.Slave.Fd()) {
+		t.Errorf("IsattyFd for tty file %v got: false want: true", p.Slave)
+	}
+	fd, err := syscall.Dup(int(p.Slave.Fd()))
+	if err != nil {
+		t.Fatalf("Dup failed: %v", err)
+	}
+	defer syscall.Close(fd)
+	if !IsattyFd(uintptr(fd)) {
+		t.Errorf("IsattyFd for dup %d of tty got: false want: true", fd)
+	}
+}
+
+// TestTerminalName checks getting the device path of a tty.
+func TestTerminalName(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	want, err := p.PTSName()
+	if err != nil {
+		t.Fatalf("PTSName failed: %v", err)
+	}
+	if got := TerminalName(p.Slave); got != want {
+		t.Errorf("TerminalName got: %q want: %q", got, want)
+	}
+	f, err := donormfile("TestTerminalName")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestTerminalName\") failed: %v", err)
+	}
+	defer f.Close()
+	if got := TerminalName(f); got != "" {
+		t.Errorf("TerminalName for normal file got: %q want: \"\"", got)
+	}
+}
+
+// TestOpenControllingTerminal tests opening /dev/tty, the tests might run without a controlling terminal.
+func TestOpenControllingTerminal(t *testing.T) {
+	name, err := CtermID()
+	if err != nil || name != "/dev/tty" {
+		t.Fatalf("CtermID() got: %q,%v want: %q,<nil>", name, err, "/dev/tty")
+	}
+	f, err := OpenControllingTerminal()
+	if errors.Is(err, syscall.ENXIO) {
+		t.Skipf("no controlling terminal: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("OpenControllingTerminal() failed: %v", err)
+	}
+	defer f.Close()
+	if !Isatty(f) {
+		t.Errorf("Isatty(OpenControllingTerminal()) got: false want: true")
+	}
+}
+
+// TestDetach tests the controlling terminal IOCTLs fail for a terminal that isn't ours.
+// The test process isn't a session leader so SetControllingTTY is refused.
+func TestDetach(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if err := Detach(tty.Slave); !errors.Is(err, syscall.ENOTTY) {
+		t.Errorf("Detach of other terminal got: %v want: %v", err, syscall.ENOTTY)
+	}
+	var ierr *IoctlError
+	if err := SetControllingTTY(tty.Slave); !errors.As(err, &ierr) || ierr.Op != "TIOCSCTTY" {
+		t.Errorf("SetControllingTTY got: %v want: *IoctlError for TIOCSCTTY", err)
+	}
+}
+
+// TestIsTerminal checks IsTerminal telling errors from not being a tty.
+func TestIsTerminal(t *testing.T) {
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	if ok, err := IsTerminal(p.Slave); !ok || err != nil {
+		t.Errorf("IsTerminal for tty got: %t, %v want: true, <nil>", ok, err)
+	}
+	f, err := donormfile("TestIsTerminal")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestIsTerminal\") failed: %v", err)
+	}
+	if ok, err := IsTerminal(f); ok || err != nil {
+		t.Errorf("IsTerminal for normal file got: %t, %v want: false, <nil>", ok, err)
+	}
+	f.Close()
+	if ok, err := IsTerminal(f); ok || !errors.Is(err, syscall.EBADF) {
+		t.Errorf("IsTerminal for closed file got: %t, %v want: false, %v", ok, err, syscall.EBADF)
+	}
+}
+
+// TestIsConnected tests telling if the other side of a PTY is gone.
+func TestIsConnected(t *testing.T) {
+	tty, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty.Close()
+	if !IsConnected(tty.Master) || !IsConnected(tty.Slave) {
+		t.Errorf("IsConnected of open PTY got Master: %t Slave: %t want: true true", IsConnected(tty.Master), IsConnected(tty.Slave))
+	}
+	if err := tty.CloseSlave(); err != nil {
+		t.Fatalf("CloseSlave failed: %v", err)
+	}
+	if IsConnected(tty.Master) {
+		t.Errorf("IsConnected of Master with Slave closed got: true want: false")
+	}
+	tty2, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer tty2.Close()
+	if err := tty2.CloseMaster(); err != nil {
+		t.Fatalf("CloseMaster failed: %v", err)
+	}
+	if IsConnected(tty2.Slave) {
+		t.Errorf("IsConnected of Slave with Master closed got: true want: false")
+	}
+	nf, err := donormfile("TestIsConnected")
+	if err != nil {
+		t.Fatalf("donormfile(\"TestIsConnected\") failed: %v", err)
+	}
+	if !IsConnected(nf) {
+		t.Errorf("IsConnected of normal file got: false want: true")
+	}
+	nf.Close()
+	if IsConnected(nf) {
+		t.Errorf("IsConnected of closed file got: true want: false")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer r.Close()
+	w.Close()
+	if !IsConnected(r) {
+		t.Errorf("IsConnected of pipe with the write end closed got: false want: true")
+	}
+}
+
+// TestWaitReadable tests waiting for the ptys with output, timing out and blocking until one has some.
+func TestWaitReadable(t *testing.T) {
+	var masters []*os.File
+	for i := 0; i < 3; i++ {
+		p, err := OpenPTYRaw()
+		if err != nil {
+			t.Fatalf("OpenPTYRaw failed: %v", err)
+		}
+		defer p.Close()
+		masters = append(masters, p.Master)
+		if i > 0 {
+			// Output of the program on the Slave, for all but the first.
+			if _, err := p.Slave.Write([]byte("out")); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+		}
+	}
+	start := time.Now()
+	ready, err := WaitReadable(masters, 2*time.Second)
+	if err != nil {
+		t.Fatalf("WaitReadable failed: %v", err)
+	}
+	// The PTYs ready could show up one by one, wait until both are.
+	for len(ready) < 2 && time.Since(start) < 2*time.Second {
+		time.Sleep(10 * time.Millisecond)
+		ready, err = WaitReadable(masters, 0)
+	}
+	if err != nil || len(ready) != 2 || ready[0] != masters[1] || ready[1] != masters[2] {
+		t.Errorf("WaitReadable got: %v,%v want: %v,<nil>", ready, err, masters[1:])
+	}
+	if _, err := WaitReadable(masters[:1], 100*time.Millisecond); err != ErrTimeout {
+		t.Errorf("WaitReadable with nothing to read got: %v want: %v", err, ErrTimeout)
+	}
+	// Blocking until the Slave writes.
+	p, err := OpenPTYRaw()
+	if err != nil {
+		t.Fatalf("OpenPTYRaw failed: %v", err)
+	}
+	defer p.Close()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.Slave.Write([]byte("late"))
+	}()
+	if ready, err := WaitReadable([]*os.File{masters[0], p.Master}, -1); err != nil || len(ready) != 1 || ready[0] != p.Master {
+		t.Errorf("WaitReadable blocking got: %v,%v want: [%v],<nil>", ready, err, p.Master)
+	}
+	if _, err := WaitReadable(nil, 0); err == nil {
+		t.Errorf("WaitReadable(nil) got: <nil> want: error")
+	}
+}
+
+// TestFlagValues tests the flag constants against the syscall ones and the attributes the kernel
+// gives a new PTY.
+func TestFlagValues(t *testing.T) {
+	for _, tst := range []struct {
+		name      string
+		got, want uint64
+	}{
+		// CRTSCTS, the CBAUD bits and EXTPROC aren't in syscall on Linux.
+		{"IGNBRK", IGNBRK, syscall.IGNBRK}, {"BRKINT", BRKINT, syscall.BRKINT},
+		{"IGNPAR", IGNPAR, syscall.IGNPAR}, {"PARMRK", PARMRK, syscall.PARMRK}, {"INPCK", INPCK, syscall.INPCK},
+		{"ISTRIP", ISTRIP, syscall.ISTRIP}, {"INLCR", INLCR, syscall.INLCR}, {"IGNCR", IGNCR, syscall.IGNCR},
+		{"ICRNL", ICRNL, syscall.ICRNL}, {"IUCLC", IUCLC, syscall.IUCLC}, {"IXON", IXON, syscall.IXON},
+		{"IXANY", IXANY, syscall.IXANY}, {"IXOFF", IXOFF, syscall.IXOFF}, {"IMAXBEL", IMAXBEL, syscall.IMAXBEL},
+		{"IUTF8", IUTF8, syscall.IUTF8}, {"OPOST", OPOST, syscall.OPOST}, {"OLCUC", OLCUC, syscall.OLCUC},
+		{"ONLCR", ONLCR, syscall.ONLCR}, {"OCRNL", OCRNL, syscall.OCRNL}, {"ONOCR", ONOCR, syscall.ONOCR},
+		{"ONLRET", ONLRET, syscall.ONLRET}, {"OFILL", OFILL, syscall.OFILL}, {"OFDEL", OFDEL, syscall.OFDEL},
+		{"CSIZE", CSIZE, syscall.CSIZE}, {"CS5", CS5, syscall.CS5}, {"CS6", CS6, syscall.CS6},
+		{"CS7", CS7, syscall.CS7}, {"CS8", CS8, syscall.CS8}, {"CSTOPB", CSTOPB, syscall.CSTOPB},
+		{"CREAD", CREAD, syscall.CREAD}, {"PARENB", PARENB, syscall.PARENB}, {"PARODD", PARODD, syscall.PARODD},
+		{"HUPCL", HUPCL, syscall.HUPCL}, {"CLOCAL", CLOCAL, syscall.CLOCAL}, {"ISIG", ISIG, syscall.ISIG},
+		{"ICANON", ICANON, syscall.ICANON}, {"XCASE", XCASE, syscall.XCASE}, {"ECHO", ECHO, syscall.ECHO},
+		{"ECHOE", ECHOE, syscall.ECHOE}, {"ECHOK", ECHOK, syscall.ECHOK}, {"ECHONL", ECHONL, syscall.ECHONL},
+		{"NOFLSH", NOFLSH, syscall.NOFLSH}, {"TOSTOP", TOSTOP, syscall.TOSTOP},
+		{"ECHOCTL", ECHOCTL, syscall.ECHOCTL}, {"ECHOPRT", ECHOPRT, syscall.ECHOPRT},
+		{"ECHOKE", ECHOKE, syscall.ECHOKE}, {"FLUSHO", FLUSHO, syscall.FLUSHO},
+		{"PENDIN", PENDIN, syscall.PENDIN}, {"IEXTEN", IEXTEN, syscall.IEXTEN}, {"VINTR", VINTR, syscall.VINTR},
+		{"VQUIT", VQUIT, syscall.VQUIT}, {"VERASE", VERASE, syscall.VERASE}, {"VKILL", VKILL, syscall.VKILL},
+		{"VEOF", VEOF, syscall.VEOF}, {"VTIME", VTIME, syscall.VTIME}, {"VMIN", VMIN, syscall.VMIN},
+		{"VSWTC", VSWTC, syscall.VSWTC}, {"VSTART", VSTART, syscall.VSTART}, {"VSTOP", VSTOP, syscall.VSTOP},
+		{"VSUSP", VSUSP, syscall.VSUSP}, {"VEOL", VEOL, syscall.VEOL}, {"VREPRINT", VREPRINT, syscall.VREPRINT},
+		{"VDISCARD", VDISCARD, syscall.VDISCARD}, {"VWERASE", VWERASE, syscall.VWERASE},
+		{"VLNEXT", VLNEXT, syscall.VLNEXT}, {"VEOL2", VEOL2, syscall.VEOL2},
+	} {
+		if tst.got != tst.want {
+			t.Errorf("%s got: %#x want: %#x", tst.name, tst.got, tst.want)
+		}
+	}
+	p, err := OpenPTY()
+	if err != nil {
+		t.Fatalf("OpenPTY failed: %v", err)
+	}
+	defer p.Close()
+	got, err := Attr(p.Slave)
+	if err != nil {
+		t.Fatalf("Attr failed: %v", err)
+	}
+	if want := uint32(ICRNL | IXON); got.Iflag != want {
+		t.Errorf("Iflag got: %o want: %o", got.Iflag, want)
+	}
+	if want := uint32(OPOST | ONLCR); got.Oflag != want {
+		t.Errorf("Oflag got: %o want: %o", got.Oflag, want)
+	}
+	if want := uint32(CS8 | CREAD); got.Cflag&^CBAUD != want {
+		t.Errorf("Cflag got: %o want: %o", got.Cflag&^CBAUD, want)
+	}
+	if want := uint32(ISIG | ICANON | ECHO | ECHOE | ECHOK | ECHOCTL | ECHOKE | IEXTEN); got.Lflag != want {
+		t.Errorf("Lflag got: %o want: %o", got.Lflag, want)
+	}
+}
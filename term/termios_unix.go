@@ -0,0 +1,288 @@
+// Copyright 2015 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin || freebsd || linux
+
+package term
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// ptmxError is returned by openPTMX when none of the devices exist, and on FreeBSD when posix_openpt fails.
+// It matches ErrNoPTMX, ErrNoPTY and the error opening the first device.
+type ptmxError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (e *ptmxError) Error() string {
+	return ErrNoPTMX.Error() + ": " + e.err.Error()
+}
+
+// Unwrap gives errors.Is and errors.As the sentinels and the open error.
+func (e *ptmxError) Unwrap() []error {
+	return []error{ErrNoPTMX, ErrNoPTY, e.err}
+}
+
+// openPTMX opens the first of the PTY multiplexer devices in paths that exists.
+// Containers and sandboxes don't always have /dev/ptmx, a devpts mount still has its own ptmx.
+// If none exist a *ptmxError is returned, any other error opening a device is returned as is.
+func openPTMX(paths ...string) (*os.File, error) {
+	var missing, other error
+	for _, path := range paths {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		switch {
+		case err == nil:
+			return f, nil
+		case errors.Is(err, fs.ErrNotExist):
+			if missing == nil {
+				missing = err
+			}
+		case other == nil:
+			other = err
+		}
+	}
+	if other != nil {
+		return nil, other
+	}
+	return nil, &ptmxError{err: missing}
+}
+
+// ioctl does the ioctl syscall cmd on fd with the argument ptr.
+// Errors are returned as *IoctlError with op naming the IOCTL.
+func ioctl(op string, fd, cmd, ptr uintptr) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, ptr)
+	if e != 0 {
+		return &IoctlError{Op: op, Cmd: cmd, Err: e}
+	}
+	return nil
+}
+
+// Isatty returns true if file is a tty.
+func Isatty(file *os.File) bool {
+	return IsattyFd(file.Fd())
+}
+
+// IsattyFd returns true if the file descriptor fd is a tty.
+// The syscall Termios matches what the kernel fills in for TCGETS on all the supported platforms.
+func IsattyFd(fd uintptr) bool {
+	var kt syscall.Termios
+	return ioctl("TCGETS", fd, TCGETS, uintptr(unsafe.Pointer(&kt))) == nil
+}
+
+// IsTerminal returns true if f is a tty.
+// Unlike Isatty it tells "not a tty" apart from the check failing, eg. because f is closed.
+// Those errors are returned along with false.
+func IsTerminal(f *os.File) (bool, error) {
+	var kt syscall.Termios
+	err := ioctl("TCGETS", f.Fd(), TCGETS, uintptr(unsafe.Pointer(&kt)))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, syscall.ENOTTY):
+		return false, nil
+	}
+	return false, err
+}
+
+// TerminalName returns the device path of the terminal f, for example /dev/pts/3.
+// An empty string is returned if f is not a tty or the path can't be found.
+func TerminalName(f *os.File) string {
+	if !Isatty(f) {
+		return ""
+	}
+	name, err := ttyname(f)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// pathTTY always refers to the controlling terminal of the process opening it.
+const pathTTY = "/dev/tty"
+
+// CtermID returns the path of the controlling terminal of the calling process, same as ctermid(3).
+// Like ctermid this is /dev/tty and doesn't tell if the process has a controlling terminal,
+// opening it fails if there is none.
+func CtermID() (string, error) {
+	if _, err := os.Stat(pathTTY); err != nil {
+		return "", err
+	}
+	return pathTTY, nil
+}
+
+// OpenControllingTerminal opens the controlling terminal of the calling process read-write.
+// Use it to prompt the user when stdin and stdout are redirected.
+func OpenControllingTerminal() (*os.File, error) {
+	name, err := CtermID()
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(name, os.O_RDWR, 0)
+}
+
+// Detach gives up f as the controlling terminal of the calling process, eg. when daemonizing.
+// f has to be the controlling terminal, if the process is the session leader the foreground
+// process group gets SIGHUP.
+func Detach(f *os.File) error {
+	return ioctl("TIOCNOTTY", f.Fd(), syscall.TIOCNOTTY, 0)
+}
+
+// SetControllingTTY makes f the controlling terminal of the calling process.
+// The process has to be a session leader without a controlling terminal, see setsid(2).
+func SetControllingTTY(f *os.File) error {
+	return ioctl("TIOCSCTTY", f.Fd(), syscall.TIOCSCTTY, 0)
+}
+
+// setBlocking clears O_NONBLOCK on f.
+func setBlocking(f *os.File) error {
+	return syscall.SetNonblock(int(f.Fd()), false)
+}
+
+// getWinsize reads the window size of the terminal file.
+func getWinsize(file *os.File) (Winsize, error) {
+	var ws Winsize
+	err := ioctl("TIOCGWINSZ", file.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+	return ws, err
+}
+
+// setWinsize sets the window size of the terminal file.
+func setWinsize(file *os.File, ws *Winsize) error {
+	return ioctl("TIOCSWINSZ", file.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(ws)))
+}
+
+// Tcgetpgrp returns the foreground process group of the terminal f.
+// A PTY Master can always be asked, other terminals only by processes having it as controlling terminal.
+func Tcgetpgrp(f *os.File) (int, error) {
+	var pgrp int32
+	err := ioctl("TIOCGPGRP", f.Fd(), syscall.TIOCGPGRP, uintptr(unsafe.Pointer(&pgrp)))
+	return int(pgrp), err
+}
+
+// Tcsetpgrp makes pgid the foreground process group of the terminal f.
+// f has to be the controlling terminal of the caller and pgid a process group in its session.
+func Tcsetpgrp(f *os.File, pgid int) error {
+	pgrp := int32(pgid)
+	return ioctl("TIOCSPGRP", f.Fd(), syscall.TIOCSPGRP, uintptr(unsafe.Pointer(&pgrp)))
+}
+
+// ModemStatus returns the state of the modem control lines of the serial port f.
+// Terminals without modem lines, eg. a PTY, return an error.
+func ModemStatus(f *os.File) (ModemBits, error) {
+	var bits int32
+	if err := ioctl("TIOCMGET", f.Fd(), TIOCMGET, uintptr(unsafe.Pointer(&bits))); err != nil {
+		return 0, err
+	}
+	return ModemBits(bits), nil
+}
+
+// SetModemBits raises the modem control lines in bits of the serial port f, the others are left as is.
+// Only the outputs ModemDTR and ModemRTS can be changed.
+func SetModemBits(f *os.File, bits ModemBits) error {
+	b := int32(bits)
+	return ioctl("TIOCMBIS", f.Fd(), TIOCMBIS, uintptr(unsafe.Pointer(&b)))
+}
+
+// ClearModemBits lowers the modem control lines in bits of the serial port f, the others are left as is.
+// Lowering and raising ModemDTR is what resets boards like the Arduino.
+func ClearModemBits(f *os.File, bits ModemBits) error {
+	b := int32(bits)
+	return ioctl("TIOCMBIC", f.Fd(), TIOCMBIC, uintptr(unsafe.Pointer(&b)))
+}
+
+// Available returns the number of bytes waiting to be read from f, a read of that many won't block.
+// For a tty in canonical mode it's what is there once a line is complete, for a PTY master the output
+// of the program on the slave. It works on pipes and sockets too.
+func Available(f *os.File) (int, error) {
+	var n int32
+	if err := ioctl("FIONREAD", f.Fd(), FIONREAD, uintptr(unsafe.Pointer(&n))); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// pollFd is the struct pollfd of poll(2).
+type pollFd struct {
+	fd      int32
+	events  int16
+	revents int16
+}
+
+// The poll(2) event bits, the same on all the supported platforms.
+const (
+	pollIn  = 0x1  // pollIn data to read
+	pollHup = 0x10 // pollHup hung up
+)
+
+// IsConnected returns false if the device of the terminal f is gone, eg. a USB serial adapter
+// unplugged, the other side of a PTY closed or the carrier lost. A closed f isn't connected either.
+// The Master of a PTY is only connected while the Slave is open, for a PTY from OpenPTYMaster
+// that's once a child opened it. Files that aren't terminals are taken as connected.
+func IsConnected(f *os.File) bool {
+	fd := f.Fd()
+	var kt syscall.Termios
+	var errno syscall.Errno
+	if err := ioctl("TCGETS", fd, TCGETS, uintptr(unsafe.Pointer(&kt))); errors.As(err, &errno) {
+		switch errno {
+		case syscall.ENXIO, syscall.EIO, syscall.EBADF:
+			return false
+		case syscall.ENOTTY, syscall.EINVAL:
+			// Not a terminal, a pipe with the other end closed isn't taken as gone.
+			return true
+		}
+	}
+	// A hung up terminal still answers TCGETS but reads give EIO, poll tells without reading.
+	pfd := []pollFd{{fd: int32(fd)}}
+	if err := poll(pfd, 0); err != nil {
+		return true
+	}
+	return pfd[0].revents&pollHup == 0
+}
+
+// WaitReadable waits for any of files to be readable and returns the ones that are, in the order
+// given. Ready means a read won't block, it may still fail, eg. on the Master of a PTY with the
+// Slave closed. ErrTimeout is returned if none is within timeout, with a negative timeout it waits
+// for as long as it takes and with 0 only checks.
+// One WaitReadable serves any number of PTY Masters, no goroutine reading each is needed.
+func WaitReadable(files []*os.File, timeout time.Duration) ([]*os.File, error) {
+	if len(files) == 0 {
+		return nil, errors.New("WaitReadable: no files")
+	}
+	fds := make([]pollFd, len(files))
+	for i, f := range files {
+		fds[i] = pollFd{fd: int32(f.Fd()), events: pollIn}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := poll(fds, timeout)
+		if err != syscall.EINTR {
+			if err != nil {
+				return nil, os.NewSyscallError("poll", err)
+			}
+			break
+		}
+		// Interrupted by a signal, eg. the Go runtime preempting, wait for what is left.
+		if timeout > 0 {
+			if timeout = time.Until(deadline); timeout < 0 {
+				timeout = 0
+			}
+		}
+	}
+	var ready []*os.File
+	for i, pfd := range fds {
+		if pfd.revents != 0 {
+			ready = append(ready, files[i])
+		}
+	}
+	if len(ready) == 0 {
+		return nil, ErrTimeout
+	}
+	return ready, nil
+}